@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxAggregateUpstreams caps how many upstreams one /aggregate call may
+// fan out to.
+const maxAggregateUpstreams = 100
+
+// aggregateTimeout is the overall deadline for an /aggregate call.
+var aggregateTimeout = 15 * time.Second
+
+type upstreamRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Method string `json:"method"`
+}
+
+// fetchAll fetches every upstream with at most concurrency in flight. Each
+// failure is recorded in its slot; with failFast the first one also cancels
+// the rest and is returned.
+func fetchAll(ctx context.Context, upstreams []upstreamRequest, concurrency int, failFast bool) ([]upstreamResult, error) {
+	results := make([]upstreamResult, len(upstreams))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i, upstream := range upstreams {
+		g.Go(func() error {
+			result, err := fetchUpstream(ctx, upstream.Method, upstream.URL)
+			if err != nil {
+				result.Error = err.Error()
+				results[i] = result
+				if failFast {
+					return fmt.Errorf("upstream %d failed: %w", i, err)
+				}
+				return nil
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	return results, g.Wait()
+}
+
+type aggregateRequest struct {
+	Requests    []upstreamRequest `json:"requests" binding:"required,dive"`
+	Concurrency int               `json:"concurrency"`
+	FailFast    bool              `json:"fail_fast"`
+}
+
+type aggregateResponse struct {
+	LatencyMs float64          `json:"latency_ms"`
+	Results   []upstreamResult `json:"results"`
+}
+
+func aggregateHandler(c *gin.Context) {
+	var payload aggregateRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if len(payload.Requests) == 0 || len(payload.Requests) > maxAggregateUpstreams {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("requests must contain between 1 and %d entries", maxAggregateUpstreams))
+		return
+	}
+	for i := range payload.Requests {
+		upstream := &payload.Requests[i]
+		if upstream.Method == "" {
+			upstream.Method = http.MethodGet
+		}
+		if status, msg := checkUpstream(upstream.Method, upstream.URL); status != 0 {
+			respondError(c, status, fmt.Errorf("requests[%d]: %s", i, msg))
+			return
+		}
+	}
+	concurrency := payload.Concurrency
+	if concurrency <= 0 || concurrency > len(payload.Requests) {
+		concurrency = len(payload.Requests)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), aggregateTimeout)
+	defer cancel()
+	start := time.Now()
+	results, err := fetchAll(ctx, payload.Requests, concurrency, payload.FailFast)
+	if respondIfCanceled(c) {
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadGateway, &APIError{
+			Code:    "upstream_error",
+			Message: err.Error(),
+			Details: map[string]interface{}{"results": results},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, aggregateResponse{LatencyMs: msSince(start), Results: results})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// delayedUpstream answers every request after delay, unless the client
+// gives up first.
+func delayedUpstream(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			w.Write([]byte("ok"))
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// aggregateBody builds an /aggregate request fetching every server.
+func aggregateBody(failFast bool, servers ...*httptest.Server) string {
+	requests := make([]string, len(servers))
+	for i, srv := range servers {
+		requests[i] = fmt.Sprintf(`{"url":%q}`, srv.URL)
+	}
+	return fmt.Sprintf(`{"requests":[%s],"fail_fast":%t}`, strings.Join(requests, ","), failFast)
+}
+
+func TestAggregateParallel(t *testing.T) {
+	delays := []time.Duration{10 * time.Millisecond, 50 * time.Millisecond, 150 * time.Millisecond}
+	var servers []*httptest.Server
+	for _, delay := range delays {
+		servers = append(servers, delayedUpstream(t, delay))
+	}
+	allowUpstreams(t, servers...)
+
+	start := time.Now()
+	w := postJSON(newRouter(), "/aggregate", aggregateBody(false, servers...))
+	elapsed := time.Since(start)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var got aggregateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Results) != len(delays) {
+		t.Fatalf("got %d results, want %d", len(got.Results), len(delays))
+	}
+	for i, result := range got.Results {
+		if result.Status != http.StatusOK || result.Bytes != 2 {
+			t.Errorf("results[%d] = %+v", i, result)
+		}
+		if min := float64(delays[i].Milliseconds()); result.LatencyMs < min {
+			t.Errorf("results[%d].latency_ms = %v, want at least %v", i, result.LatencyMs, min)
+		}
+	}
+	// Run one after another the upstreams would take 210ms.
+	if elapsed >= 200*time.Millisecond {
+		t.Errorf("aggregation took %v, upstreams were not fetched in parallel", elapsed)
+	}
+}
+
+func TestAggregateFailure(t *testing.T) {
+	fast := delayedUpstream(t, 0)
+	slow := delayedUpstream(t, 2*time.Second)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	allowUpstreams(t, fast, down, slow)
+	r := newRouter()
+
+	// Without fail_fast the failure is reported in its slot and the others
+	// still complete.
+	w := postJSON(r, "/aggregate", aggregateBody(false, fast, down))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var got aggregateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Results[0].Status != http.StatusOK || got.Results[0].Error != "" {
+		t.Errorf("results[0] = %+v, want success", got.Results[0])
+	}
+	if got.Results[1].Error == "" {
+		t.Errorf("results[1] = %+v, want an error", got.Results[1])
+	}
+
+	// With fail_fast the failure cancels the slow upstream instead of
+	// waiting out its delay.
+	start := time.Now()
+	w = postJSON(r, "/aggregate", aggregateBody(true, slow, down))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("fail_fast: status = %d, want 502, body %s", w.Code, w.Body)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("fail_fast took %v, the slow upstream was not canceled", elapsed)
+	}
+	if code := errorCode(t, w); code != "upstream_error" {
+		t.Errorf("code = %q, want upstream_error", code)
+	}
+}
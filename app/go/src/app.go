@@ -1,126 +1,269 @@
 package main
 
 import (
-	"bytes"
-	"compress/gzip"
-	"encoding/base64"
-	"encoding/json"
+	"context"
+	"log/slog"
 	"net/http"
-	"regexp"
-	"sync"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-type lazyLoader struct {
-	mathLoaded   sync.Once
-	imageLoaded  sync.Once
+// envInt reads a positive integer from the environment, falling back to def
+// when the variable is unset or invalid.
+func envInt(name string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
 }
 
-var loader = &lazyLoader{}
+// envMillis reads a duration expressed in milliseconds from the environment,
+// falling back to def when the variable is unset or not a positive integer.
+func envMillis(name string, def time.Duration) time.Duration {
+	return time.Duration(envInt(name, int(def/time.Millisecond))) * time.Millisecond
+}
 
-func main() {
-	r := gin.Default()
-
-	// Middleware to calculate execution time
-	r.Use(func(c *gin.Context) {
-		lambdaStart := time.Now()
-		c.Set("lambdaStart", lambdaStart)
-		c.Next()
-		lambdaEnd := time.Now()
-
-		c.Header("X-Lambda-Start-Time", lambdaStart.String())
-		c.Header("X-Lambda-End-Time", lambdaEnd.String())
-		c.Header("X-Lambda-Duration", lambdaEnd.Sub(lambdaStart).String())
-	})
-
-	r.POST("/math", func(c *gin.Context) {
-		var payload struct {
-			Numbers   []float64 `json:"numbers"`
-			Operation string   `json:"operation"`
-		}
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+// jitterSeed returns JITTER_SEED, which may be any int64 including zero or a
+// negative value, seeding from the clock only when the variable is unset or
+// unparsable.
+func jitterSeed() int64 {
+	raw, ok := os.LookupEnv("JITTER_SEED")
+	if !ok {
+		return time.Now().UnixNano()
+	}
+	seed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		slog.Warn("ignoring invalid JITTER_SEED", "value", raw)
+		return time.Now().UnixNano()
+	}
+	return seed
+}
 
-		var result float64
-		if payload.Operation == "sum" {
-			for _, num := range payload.Numbers {
-				result += num
-			}
-		} else if payload.Operation == "product" {
-			result = 1
-			for _, num := range payload.Numbers {
-				result *= num
-			}
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported operation"})
-			return
-		}
+// resolvePort returns the listen port from the PORT environment variable,
+// falling back to 8080 when it is unset or not a valid TCP port.
+func resolvePort() string {
+	port, err := strconv.Atoi(os.Getenv("PORT"))
+	if err != nil || port < 1 || port > 65535 {
+		return "8080"
+	}
+	return strconv.Itoa(port)
+}
 
-		c.JSON(http.StatusOK, gin.H{"result": result})
-	})
+type healthResponse struct {
+	Status string `json:"status"`
+}
 
-	r.POST("/json", func(c *gin.Context) {
-		var payload struct {
-			Key   string `json:"key"`
-			Value string `json:"value"`
-		}
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+func healthHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+}
 
-		jsonData, _ := json.Marshal(map[string]string{payload.Key: payload.Value})
-		c.JSON(http.StatusOK, gin.H{"json_data": string(jsonData)})
-	})
+// newRouter builds the engine shared by the HTTP and Lambda entrypoints.
+func newRouter() *gin.Engine {
+	r := gin.New()
+	// Already gin's default; set explicitly because clients rely on /math/
+	// redirecting to /math (307 for non-GET, so the body is resent) rather
+	// than answering 404.
+	r.RedirectTrailingSlash = true
+	r.Use(requestIDMiddleware, accessLogger, recoveryMiddleware, corsMiddleware(os.Getenv("CORS_ALLOWED_ORIGINS")), bodyLimitMiddleware, gzipRequestMiddleware)
 
-	r.POST("/string", func(c *gin.Context) {
-		var payload struct {
-			Text    string `json:"text"`
-			Pattern string `json:"pattern"`
-		}
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	// Registered ahead of the timing middleware so probes stay cheap.
+	r.GET("/health", healthHandler)
+	r.GET("/version", versionHandler)
+	r.GET("/metrics", metricsHandler)
+	r.GET("/stats", statsHandler)
+	r.GET("/debug/memstats", memStatsHandler)
+	r.GET("/openapi.json", openAPIHandler(r))
+	pprofEnabled := os.Getenv("ENABLE_PPROF") == "true"
+	r.GET("/routes", routesHandler(r, pprofEnabled))
+	if pprofEnabled {
+		registerPprof(r)
+	}
 
-		re := regexp.MustCompile(payload.Pattern)
-		matches := re.FindAllString(payload.Text, -1)
-		c.JSON(http.StatusOK, gin.H{"matches": matches})
-	})
+	r.Use(gzipResponseMiddleware)
+	// Inside the gzip middleware, so it logs the body the handler wrote rather
+	// than the compressed bytes.
+	if os.Getenv("DEBUG_BODIES") == "true" {
+		r.Use(bodyLoggerMiddleware)
+	}
 
-	r.POST("/compress", func(c *gin.Context) {
-		var payload struct {
-			Text string `json:"text"`
-		}
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	// Middleware to calculate execution time. It runs ahead of the limiters
+	// so their rejections are timed and counted in the metrics too.
+	r.Use(timingMiddleware)
 
-		var buf bytes.Buffer
-		gzipWriter := gzip.NewWriter(&buf)
-		_, _ = gzipWriter.Write([]byte(payload.Text))
-		gzipWriter.Close()
-		c.Data(http.StatusOK, "application/gzip", buf.Bytes())
-	})
+	// The limiters only guard the work routes: a saturated server must still
+	// answer its probes.
+	if n := envInt("MAX_INFLIGHT", 0); n > 0 {
+		r.Use(inflightLimiter(n))
+	}
+	if slots := envInt("QUEUE_SLOTS", 0); slots > 0 {
+		r.Use(queueLimiter(slots, envInt("QUEUE_DEPTH", slots), envMillis("QUEUE_MAX_WAIT_MS", time.Second)))
+	}
+	if rps := envInt("RATE_LIMIT_RPS", 0); rps > 0 {
+		r.Use(rateLimiter(rps, envInt("RATE_LIMIT_BURST", rps), os.Getenv("RATE_LIMIT_PER_IP") == "true"))
+	}
 
-	r.POST("/image", func(c *gin.Context) {
-		var payload struct {
-			Text string `json:"text"`
-		}
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
+	r.Use(timeoutMiddleware, cpuBurnMiddleware)
+	if mode := os.Getenv("JITTER_MODE"); jitterModes[mode] {
+		r.Use(jitterMiddleware(newJitterSource(mode, envMillis("JITTER_MEAN_MS", 0), envMillis("JITTER_STDDEV_MS", 0), jitterSeed())))
+	} else if mode != "" {
+		slog.Warn("ignoring unknown JITTER_MODE", "mode", mode)
+	}
+	if os.Getenv("ENABLE_ETAG") == "true" {
+		r.Use(etagMiddleware)
+	}
+	if os.Getenv("ENABLE_IDEMPOTENCY") == "true" {
+		r.Use(idempotencyMiddleware(newIdempotencyStore(envMillis("IDEMPOTENCY_TTL_MS", 10*time.Minute), envInt("IDEMPOTENCY_MAX_KEYS", 10000))))
+	}
+	// Singleflight goes last: its shared run calls the route handler directly.
+	if os.Getenv("ENABLE_SINGLEFLIGHT") == "true" {
+		r.Use(singleflightMiddleware(r))
+	}
+
+	r.POST("/math", mathHandler)
+	r.POST("/math/batch", mathBatchHandler)
+	r.POST("/math/expression", mathExpressionHandler)
+	r.POST("/math/fft", mathFFTHandler)
+	r.POST("/math/interest", mathInterestHandler)
+	r.POST("/math/matrix", mathMatrixHandler)
+	r.POST("/math/primes", mathPrimesHandler)
+	r.POST("/math/vector", mathVectorHandler)
+	r.POST("/json", jsonHandler)
+	r.POST("/json/echo", jsonEchoHandler)
+	r.POST("/json/flatten", jsonFlattenHandler)
+	r.POST("/json/merge", jsonMergeHandler)
+	r.POST("/json/select", jsonSelectHandler)
+	r.POST("/json/validate", jsonValidateHandler)
+	r.POST("/string", stringHandler)
+	r.POST("/string/split", stringSplitHandler)
+	r.POST("/compress", compressHandler)
+	r.POST("/compress/stream", compressStreamHandler)
+	r.POST("/decompress", decompressHandler)
+	r.POST("/hash", hashHandler)
+	r.POST("/hmac/sign", hmacSignHandler)
+	r.POST("/hmac/verify", hmacVerifyHandler)
+	r.POST("/jwt/sign", jwtSignHandler)
+	r.POST("/jwt/verify", jwtVerifyHandler)
+	r.POST("/proxy", proxyHandler)
+	r.POST("/aggregate", aggregateHandler)
+	r.POST("/encode", encodeHandler)
+	r.POST("/decode", decodeHandler)
+	r.POST("/image", imageHandler)
+	r.POST("/fibonacci", fibonacciHandler)
+	r.POST("/random", randomHandler)
+	r.POST("/convert", convertHandler)
+	r.POST("/sleep", sleepHandler)
+	r.POST("/warmup", warmupHandler)
+	r.POST("/memory", memoryHandler)
+	r.GET("/echo", echoHandler)
+	r.POST("/echo", echoHandler)
+
+	return r
+}
+
+func main() {
+	slog.SetDefault(newLogger(os.Getenv("LOG_FORMAT"), os.Getenv("LOG_LEVEL")))
+	stringMatchTimeout = envMillis("STRING_MATCH_TIMEOUT_MS", stringMatchTimeout)
+	maxBodyBytes = int64(envInt("MAX_BODY_BYTES", int(maxBodyBytes)))
+	shutdownGrace = envMillis("SHUTDOWN_GRACE_MS", shutdownGrace)
+	readHeaderTimeout = envMillis("READ_HEADER_TIMEOUT_MS", readHeaderTimeout)
+	readTimeout = envMillis("READ_TIMEOUT_MS", readTimeout)
+	writeTimeout = envMillis("WRITE_TIMEOUT_MS", writeTimeout)
+	idleTimeout = envMillis("IDLE_TIMEOUT_MS", idleTimeout)
+	maxFibonacciN = envInt("FIBONACCI_MAX_N", maxFibonacciN)
+	maxRandomCount = envInt("RANDOM_MAX_COUNT", maxRandomCount)
+	maxPrimesLimit = envInt("PRIMES_MAX_LIMIT", maxPrimesLimit)
+	maxFFTSize = envInt("FFT_MAX_SIZE", maxFFTSize)
+	maxInterestPeriods = envInt("INTEREST_MAX_PERIODS", maxInterestPeriods)
+	coldStartWindow = envMillis("COLD_START_WINDOW_MS", coldStartWindow)
+	statsBufferSize = envInt("STATS_BUFFER_SIZE", statsBufferSize)
+	maxCPUBurn = envMillis("CPU_BURN_MAX_MS", maxCPUBurn)
+	maxMemoryMB = envInt("MEMORY_MAX_MB", maxMemoryMB)
+	handlerTimeout = envMillis("HANDLER_TIMEOUT_MS", handlerTimeout)
+	routeTimeouts = parseRouteTimeouts(os.Getenv("ROUTE_TIMEOUTS"))
+	proxyTimeout = envMillis("PROXY_TIMEOUT_MS", proxyTimeout)
+	outbound := outboundConfig{
+		Timeout:             proxyTimeout,
+		MaxIdleConns:        envInt("OUTBOUND_MAX_IDLE_CONNS", defaultOutboundConfig.MaxIdleConns),
+		MaxIdleConnsPerHost: envInt("OUTBOUND_MAX_IDLE_CONNS_PER_HOST", defaultOutboundConfig.MaxIdleConnsPerHost),
+		IdleConnTimeout:     envMillis("OUTBOUND_IDLE_CONN_TIMEOUT_MS", defaultOutboundConfig.IdleConnTimeout),
+		DisableKeepAlives:   os.Getenv("OUTBOUND_DISABLE_KEEPALIVES") == "true",
+	}
+	outboundClient = newOutboundClient(outbound)
+	slog.Info("outbound client",
+		"timeout", outbound.Timeout.String(),
+		"max_idle_conns", outbound.MaxIdleConns,
+		"max_idle_conns_per_host", outbound.MaxIdleConnsPerHost,
+		"idle_conn_timeout", outbound.IdleConnTimeout.String(),
+		"disable_keepalives", outbound.DisableKeepAlives,
+	)
+	proxyAllowedHosts = parseHostList(os.Getenv("PROXY_ALLOWED_HOSTS"))
+	aggregateTimeout = envMillis("AGGREGATE_TIMEOUT_MS", aggregateTimeout)
+	if path := os.Getenv("CONVERT_RATES_FILE"); path != "" {
+		table, err := loadRateTable(path)
+		if err != nil {
+			slog.Error("rate table load failed", "path", path, "error", err)
+			os.Exit(1)
 		}
+		conversionRates.Store(&table)
+		reloadRatesOnSignal(path)
+	}
+
+	r := newRouter()
+	var handler http.Handler = r
+	if os.Getenv("CASE_INSENSITIVE_ROUTES") == "true" {
+		handler = foldPathCase(r)
+	}
+
+	if os.Getenv("RUNTIME_MODE") == "lambda" {
+		slog.Info("starting in lambda mode")
+		startLambda(handler)
+		return
+	}
 
-		// Dummy image creation simulation
-		encodedImage := base64.StdEncoding.EncodeToString([]byte("fake_image_data"))
-		c.JSON(http.StatusOK, gin.H{"image": encodedImage})
-	})
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	r.Run(":8080")
+	port := resolvePort()
+	h2cEnabled := os.Getenv("HTTP2_H2C") == "true"
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           serverHandler(handler, h2cEnabled),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	slog.Info("server timeouts",
+		"read_header_timeout", readHeaderTimeout.String(),
+		"read_timeout", readTimeout.String(),
+		"write_timeout", writeTimeout.String(),
+		"idle_timeout", idleTimeout.String(),
+	)
+	if os.Getenv("TLS_ENABLED") == "true" {
+		certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+		tlsConfig, err := newTLSConfig(certFile, keyFile)
+		if err != nil {
+			slog.Error("tls setup failed", "error", err)
+			os.Exit(1)
+		}
+		srv.TLSConfig = tlsConfig
+		if certFile == "" || keyFile == "" {
+			slog.Info("tls enabled with a generated self-signed certificate")
+		} else {
+			slog.Info("tls enabled", "cert_file", certFile)
+		}
+	} else {
+		slog.Info("tls disabled, serving plaintext")
+	}
+	slog.Info("listening", "port", port, "h2c", h2cEnabled)
+	if err := runServer(ctx, srv, shutdownGrace); err != nil {
+		slog.Error("server error", "error", err)
+		os.Exit(1)
+	}
 }
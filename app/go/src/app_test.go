@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// serve runs req through h and returns the recorded response.
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// postJSON sends body to path as application/json.
+func postJSON(h http.Handler, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return serve(h, req)
+}
+
+// decodeBody unmarshals a JSON object response, failing the test otherwise.
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not a JSON object: %v: %q", err, w.Body.String())
+	}
+	return body
+}
+
+// errorCode returns the code of an error envelope response.
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	apiErr, _ := decodeBody(t, w)["error"].(map[string]interface{})
+	code, _ := apiErr["code"].(string)
+	return code
+}
+
+func TestHandlersHappyPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		body    string
+		check   func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "math",
+			handler: mathHandler,
+			body:    `{"operation":"sum","numbers":[1,2,3.5]}`,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				if got := decodeBody(t, w)["result"]; got != 6.5 {
+					t.Errorf("result = %v, want 6.5", got)
+				}
+			},
+		},
+		{
+			name:    "json",
+			handler: jsonHandler,
+			body:    `{"key":"greeting","value":"hello"}`,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				if got := decodeBody(t, w)["json_data"]; got != `{"greeting":"hello"}` {
+					t.Errorf("json_data = %v", got)
+				}
+			},
+		},
+		{
+			name:    "string",
+			handler: stringHandler,
+			body:    `{"text":"a1b22c333","pattern":"[0-9]+"}`,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				matches, _ := decodeBody(t, w)["matches"].([]interface{})
+				if len(matches) != 3 || matches[2] != "333" {
+					t.Errorf("matches = %v", matches)
+				}
+			},
+		},
+		{
+			name:    "compress",
+			handler: compressHandler,
+			body:    `{"text":"hello hello hello"}`,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				if got := w.Header().Get("Content-Type"); got != "application/gzip" {
+					t.Errorf("Content-Type = %q", got)
+				}
+				if data := w.Body.Bytes(); len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+					t.Errorf("body is not gzip: %x", data)
+				}
+			},
+		},
+		{
+			name:    "image",
+			handler: imageHandler,
+			body:    `{"text":"hi","width":8,"height":4}`,
+			check: func(t *testing.T, w *httptest.ResponseRecorder) {
+				if image, _ := decodeBody(t, w)["image"].(string); image == "" {
+					t.Error("image missing")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			tt.handler(c)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", w.Code, w.Body)
+			}
+			tt.check(t, w)
+		})
+	}
+}
+
+func TestResolvePort(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", "8080"},
+		{"abc", "8080"},
+		{"0", "8080"},
+		{"65536", "8080"},
+		{"-1", "8080"},
+		{"1", "1"},
+		{"9090", "9090"},
+		{"65535", "65535"},
+	}
+	for _, tt := range tests {
+		t.Setenv("PORT", tt.env)
+		if got := resolvePort(); got != tt.want {
+			t.Errorf("PORT=%q: resolvePort() = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	w := serve(newRouter(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if got := strings.TrimSpace(w.Body.String()); got != `{"status":"ok"}` {
+		t.Errorf("body = %s", got)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	w := serve(newRouter(), httptest.NewRequest(http.MethodGet, "/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	body := decodeBody(t, w)
+	if got := body["go_version"]; got != runtime.Version() {
+		t.Errorf("go_version = %v, want %s", got, runtime.Version())
+	}
+	for _, key := range []string{"version", "commit", "build_time"} {
+		if got := body[key]; got != "dev" {
+			t.Errorf("%s = %v, want the dev default", key, got)
+		}
+	}
+}
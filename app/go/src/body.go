@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// maxBodyBytes caps the size of any request body.
+var maxBodyBytes int64 = 1 << 20
+
+// streamedBodyRoutes consume their body incrementally without buffering it,
+// so they are exempt from maxBodyBytes.
+var streamedBodyRoutes = map[string]bool{
+	"/compress/stream": true,
+}
+
+func bodyLimitMiddleware(c *gin.Context) {
+	if streamedBodyRoutes[c.FullPath()] {
+		c.Next()
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes)
+	c.Next()
+}
+
+// compressedBodyRoutes read gzip bodies themselves, so gzipRequestMiddleware
+// leaves their Content-Encoding alone.
+var compressedBodyRoutes = map[string]bool{
+	"/decompress": true,
+}
+
+// gzipRequestMiddleware transparently decompresses request bodies sent with
+// Content-Encoding: gzip, answering 400 if the gzip header is corrupt. The
+// decompressed stream is capped at maxBodyBytes again, so a small body can't
+// inflate past the limit.
+func gzipRequestMiddleware(c *gin.Context) {
+	route := c.FullPath()
+	if !strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") || compressedBodyRoutes[route] {
+		c.Next()
+		return
+	}
+	gzipReader, err := gzip.NewReader(c.Request.Body)
+	if err != nil {
+		decompressError(c, err)
+		return
+	}
+	c.Request.Body = gzipReader
+	if !streamedBodyRoutes[route] {
+		c.Request.Body = http.MaxBytesReader(c.Writer, gzipReader, maxBodyBytes)
+	}
+	c.Request.Header.Del("Content-Encoding")
+	c.Request.Header.Del("Content-Length")
+	c.Request.ContentLength = -1
+	c.Next()
+}
+
+// bufferBody reads the whole request body and replaces it with an in-memory
+// copy, so middleware can inspect it and the handler still binds it. On a
+// read error it responds and returns false.
+func bufferBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		if isBodyTooLarge(err) {
+			respondError(c, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+			return nil, false
+		}
+		respondError(c, http.StatusBadRequest, err)
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+func init() {
+	// Report validation failures using JSON field names, not Go ones.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// fieldError describes one failed validation rule.
+type fieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// formatValidationErrors flattens validator errors into field/reason pairs,
+// e.g. {"field":"operation","reason":"required"}.
+func formatValidationErrors(errs validator.ValidationErrors) []fieldError {
+	out := make([]fieldError, len(errs))
+	for i, fe := range errs {
+		reason := fe.Tag()
+		switch {
+		case strings.HasPrefix(reason, "required"):
+			// Conditional variants reference Go field names; callers only
+			// need to know the field was missing.
+			reason = "required"
+		case fe.Param() != "":
+			reason += "=" + fe.Param()
+		}
+		out[i] = fieldError{Field: fe.Field(), Reason: reason}
+	}
+	return out
+}
+
+// bindJSON decodes and validates the request body into obj, writing a 413 or
+// 400 response and returning false if it cannot.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	return checkBind(c, c.ShouldBindJSON(obj))
+}
+
+// bindXML is bindJSON for XML request bodies.
+func bindXML(c *gin.Context, obj interface{}) bool {
+	return checkBind(c, c.ShouldBindXML(obj))
+}
+
+// bindMsgPack is bindJSON for MessagePack request bodies. Only decoding is
+// checked; binding tags are not validated.
+func bindMsgPack(c *gin.Context, obj interface{}) bool {
+	return checkBind(c, msgpack.NewDecoder(c.Request.Body).Decode(obj))
+}
+
+// checkBind writes the response for a failed bind and reports whether err
+// was nil.
+func checkBind(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	var validationErrs validator.ValidationErrors
+	switch {
+	case isBodyTooLarge(err):
+		respondError(c, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+	case errors.As(err, &validationErrs):
+		respondError(c, http.StatusBadRequest, &APIError{
+			Code:    "validation_failed",
+			Message: "request validation failed",
+			Details: map[string]interface{}{"fields": formatValidationErrors(validationErrs)},
+		})
+	default:
+		respondError(c, http.StatusBadRequest, err)
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// withMaxBodyBytes sets maxBodyBytes for the rest of the test.
+func withMaxBodyBytes(t *testing.T, n int64) {
+	previous := maxBodyBytes
+	maxBodyBytes = n
+	t.Cleanup(func() { maxBodyBytes = previous })
+}
+
+// paddedBody fills the single %s in template with enough filler to make the
+// body exactly size bytes.
+func paddedBody(template string, size int) string {
+	return strings.Replace(template, "%s", strings.Repeat("a", size-len(template)+2), 1)
+}
+
+func TestBodyLimit(t *testing.T) {
+	withMaxBodyBytes(t, 1024)
+	r := newRouter()
+	tests := []struct{ path, template string }{
+		{"/compress", `{"text":"%s"}`},
+		{"/hash", `{"algorithm":"sha256","text":"%s"}`},
+		{"/json", `{"key":"k","value":"%s"}`},
+	}
+	for _, tt := range tests {
+		if w := postJSON(r, tt.path, paddedBody(tt.template, 1024)); w.Code != http.StatusOK {
+			t.Errorf("%s at the limit: status = %d, body %s", tt.path, w.Code, w.Body)
+		}
+		w := postJSON(r, tt.path, paddedBody(tt.template, 1025))
+		if w.Code != http.StatusRequestEntityTooLarge || errorCode(t, w) != "body_too_large" {
+			t.Errorf("%s one byte over: status = %d, body %s", tt.path, w.Code, w.Body)
+		}
+	}
+}
+
+func TestFormatValidationErrors(t *testing.T) {
+	err := binding.Validator.ValidateStruct(&jsonMergeRequest{Objects: []map[string]interface{}{}})
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ValidateStruct = %v, want validation errors", err)
+	}
+	got := formatValidationErrors(errs)
+	if len(got) != 1 || got[0] != (fieldError{Field: "objects", Reason: "min=1"}) {
+		t.Errorf("formatValidationErrors = %v", got)
+	}
+
+	w := postJSON(newRouter(), "/math", `{"numbers":[1]}`)
+	if w.Code != http.StatusBadRequest || errorCode(t, w) != "validation_failed" {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	apiErr, _ := decodeBody(t, w)["error"].(map[string]interface{})
+	details, _ := apiErr["details"].(map[string]interface{})
+	fields, _ := details["fields"].([]interface{})
+	if len(fields) != 1 {
+		t.Fatalf("fields = %v", details["fields"])
+	}
+	if field, _ := fields[0].(map[string]interface{}); field["field"] != "operation" || field["reason"] != "required" {
+		t.Errorf("field = %v, want operation/required", field)
+	}
+}
+
+// postGzip sends body to path gzip-compressed as application/json.
+func postGzip(h http.Handler, path string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	return serve(h, req)
+}
+
+// gzipped returns data gzip-compressed.
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGzipRequestBody(t *testing.T) {
+	r := newRouter()
+	w := postGzip(r, "/math", gzipped(t, `{"operation":"sum","numbers":[1,2,3.5]}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if got := decodeBody(t, w)["result"]; got != 6.5 {
+		t.Errorf("result = %v, want 6.5", got)
+	}
+
+	full := gzipped(t, `{"operation":"sum","numbers":[1,2,3.5]}`)
+	for name, body := range map[string][]byte{
+		"not gzip":       []byte(`{"operation":"sum","numbers":[1]}`),
+		"truncated gzip": full[:len(full)-12],
+	} {
+		if w := postGzip(r, "/math", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+}
+
+func TestGzipRequestBodyLimit(t *testing.T) {
+	withMaxBodyBytes(t, 1024)
+	// Well under the limit compressed, over it once inflated.
+	body := gzipped(t, paddedBody(`{"key":"k","value":"%s"}`, 4096))
+	if len(body) >= 1024 {
+		t.Fatalf("compressed body is %d bytes", len(body))
+	}
+	if w := postGzip(newRouter(), "/json", body); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
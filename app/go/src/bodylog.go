@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLoggedBodyBytes caps how much of each body the debug logger keeps.
+const maxLoggedBodyBytes = 4 << 10
+
+// cappedBuffer keeps the first maxLoggedBodyBytes written to it and discards
+// the rest, always reporting a full write.
+type cappedBuffer struct {
+	data []byte
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxLoggedBodyBytes - len(b.data); room > 0 {
+		b.data = append(b.data, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// teeBody copies what the handler reads from the request body into a
+// cappedBuffer, so the body is never consumed on the handler's behalf.
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body *cappedBuffer
+}
+
+func (w *bodyLogWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.body.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// bodyLoggerMiddleware logs request and response bodies, capped at 4KB each.
+// It is enabled with DEBUG_BODIES=true.
+func bodyLoggerMiddleware(c *gin.Context) {
+	requestBody := &cappedBuffer{}
+	c.Request.Body = teeBody{Reader: io.TeeReader(c.Request.Body, requestBody), Closer: c.Request.Body}
+
+	responseBody := &cappedBuffer{}
+	c.Writer = &bodyLogWriter{ResponseWriter: c.Writer, body: responseBody}
+
+	c.Next()
+
+	requestLogger(c).Info("bodies",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"request_body", string(requestBody.data),
+		"response_body", string(responseBody.data),
+	)
+}
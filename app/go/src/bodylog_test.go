@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLoggerKeepsBodyReadable(t *testing.T) {
+	t.Setenv("DEBUG_BODIES", "true")
+	var logs bytes.Buffer
+	useDefaultLogger(t, slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	body := `{"operation":"sum","numbers":[1,2,3]}`
+	w := postJSON(newRouter(), "/math", body)
+	if w.Code != http.StatusOK || decodeBody(t, w)["result"] != 6.0 {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	var logged bool
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]interface{}
+		if json.Unmarshal([]byte(line), &record) != nil || record["msg"] != "bodies" {
+			continue
+		}
+		logged = true
+		if record["request_body"] != body || record["response_body"] != strings.TrimSpace(w.Body.String()) {
+			t.Errorf("bodies record = %v", record)
+		}
+	}
+	if !logged {
+		t.Errorf("no bodies record in %s", logs.String())
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	var b cappedBuffer
+	for i := 0; i < 3; i++ {
+		if n, err := b.Write(bytes.Repeat([]byte("x"), 2000)); n != 2000 || err != nil {
+			t.Fatalf("Write = %d, %v", n, err)
+		}
+	}
+	if len(b.data) != maxLoggedBodyBytes {
+		t.Errorf("kept %d bytes, want %d", len(b.data), maxLoggedBodyBytes)
+	}
+}
+
+func TestBodyLoggerWithGzipResponse(t *testing.T) {
+	t.Setenv("DEBUG_BODIES", "true")
+	var logs bytes.Buffer
+	useDefaultLogger(t, slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	// /string with many matches answers well over gzipMinBytes.
+	body := `{"text":"` + strings.Repeat("a1 ", 1000) + `","pattern":"[0-9]"}`
+	req := httptest.NewRequest(http.MethodPost, "/string", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := serve(newRouter(), req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("status = %d, Content-Encoding %q", w.Code, w.Header().Get("Content-Encoding"))
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]interface{}
+		if json.Unmarshal([]byte(line), &record) != nil || record["msg"] != "bodies" {
+			continue
+		}
+		logged, _ := record["response_body"].(string)
+		if !strings.HasPrefix(logged, `{"matches":["1","1",`) {
+			t.Errorf("logged response_body starts %q, want the uncompressed JSON", logged[:min(len(logged), 40)])
+		}
+		return
+	}
+	t.Errorf("no bodies record in %s", logs.String())
+}
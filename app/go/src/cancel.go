@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is the non-standard 499 used when the client
+// goes away before the response is ready.
+const statusClientClosedRequest = 499
+
+// respondIfCanceled returns true when the request context is done, letting
+// handlers bail out at convenient checkpoints. It writes a 504 if the route
+// deadline passed and a 499 if the client went away.
+func respondIfCanceled(c *gin.Context) bool {
+	switch c.Request.Context().Err() {
+	case nil:
+		return false
+	case context.DeadlineExceeded:
+		respondError(c, http.StatusGatewayTimeout, errors.New("handler timeout"))
+	default:
+		respondError(c, statusClientClosedRequest, errors.New("request canceled"))
+	}
+	return true
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// compressor describes one algorithm /compress can produce.
+type compressor struct {
+	contentType  string
+	defaultLevel int
+	minLevel     int
+	maxLevel     int
+	newWriter    func(w io.Writer, level int) (io.WriteCloser, error)
+}
+
+var compressors = map[string]compressor{
+	"gzip": {
+		contentType:  "application/gzip",
+		defaultLevel: gzip.DefaultCompression,
+		minLevel:     gzip.DefaultCompression,
+		maxLevel:     gzip.BestCompression,
+		newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+	},
+	"deflate": {
+		contentType:  "application/x-deflate",
+		defaultLevel: flate.DefaultCompression,
+		minLevel:     flate.DefaultCompression,
+		maxLevel:     flate.BestCompression,
+		newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return flate.NewWriter(w, level)
+		},
+	},
+	"zlib": {
+		contentType:  "application/zlib",
+		defaultLevel: zlib.DefaultCompression,
+		minLevel:     zlib.DefaultCompression,
+		maxLevel:     zlib.BestCompression,
+		newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return zlib.NewWriterLevel(w, level)
+		},
+	},
+	"brotli": {
+		contentType:  "application/x-brotli",
+		defaultLevel: brotli.DefaultCompression,
+		minLevel:     brotli.BestSpeed,
+		maxLevel:     brotli.BestCompression,
+		newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return brotli.NewWriterLevel(w, level), nil
+		},
+	},
+}
+
+type compressRequest struct {
+	Text      string `json:"text"`
+	Level     *int   `json:"level"`
+	Algorithm string `json:"algorithm"`
+}
+
+type compressResponse struct {
+	Compressed string `json:"compressed"`
+}
+
+func compressHandler(c *gin.Context) {
+	var payload compressRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	if payload.Algorithm == "" {
+		payload.Algorithm = "gzip"
+	}
+	algo, ok := compressors[payload.Algorithm]
+	if !ok {
+		respondError(c, http.StatusBadRequest, newAPIError("unsupported_algorithm", "unsupported algorithm: "+payload.Algorithm))
+		return
+	}
+
+	level := algo.defaultLevel
+	if payload.Level != nil {
+		level = *payload.Level
+	}
+	if level < algo.minLevel || level > algo.maxLevel {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("level must be between %d and %d for %s", algo.minLevel, algo.maxLevel, payload.Algorithm))
+		return
+	}
+
+	var buf bytes.Buffer
+	writer, err := algo.newWriter(&buf, level)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := writeChunks(c.Request.Context(), writer, []byte(payload.Text)); err != nil {
+		if !respondIfCanceled(c) {
+			respondError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	writer.Close()
+	c.Header("X-Compression-Algorithm", payload.Algorithm)
+	c.Header("X-Compression-Level", strconv.Itoa(level))
+	c.Header("X-Original-Bytes", strconv.Itoa(len(payload.Text)))
+	c.Header("X-Compressed-Bytes", strconv.Itoa(buf.Len()))
+	c.Header("X-Compression-Ratio", strconv.FormatFloat(compressionRatio(len(payload.Text), buf.Len()), 'f', -1, 64))
+
+	// Clients that prefer JSON get application/json with base64 bytes;
+	// everyone else, including */* and no Accept at all, gets the raw stream
+	// under the algorithm's own content type.
+	if c.NegotiateFormat(algo.contentType, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, compressResponse{Compressed: base64.StdEncoding.EncodeToString(buf.Bytes())})
+		return
+	}
+	c.Data(http.StatusOK, algo.contentType, buf.Bytes())
+}
+
+const compressChunkSize = 64 << 10
+
+// writeChunks feeds data to w in fixed-size chunks, stopping early with the
+// context error once ctx is done.
+func writeChunks(ctx context.Context, w io.Writer, data []byte) error {
+	for len(data) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := min(len(data), compressChunkSize)
+		if _, err := w.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// compressionRatio returns compressed/original, or 0 for empty input where
+// the ratio is undefined.
+func compressionRatio(original, compressed int) float64 {
+	if original == 0 {
+		return 0
+	}
+	return float64(compressed) / float64(original)
+}
+
+// compressStreamHandler gzips the raw request body straight into the
+// response, so neither side is held in memory.
+func compressStreamHandler(c *gin.Context) {
+	contentType := c.ContentType()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Encoding", "gzip")
+	c.Status(http.StatusOK)
+
+	writer := gzip.NewWriter(c.Writer)
+	if _, err := io.Copy(writer, c.Request.Body); err != nil {
+		// Once bytes are on the wire the status can't change; the client
+		// sees a truncated gzip stream instead.
+		if !c.Writer.Written() {
+			c.Header("Content-Encoding", "")
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.Error(err)
+		return
+	}
+	writer.Close()
+}
+
+type decompressResponse struct {
+	Text string `json:"text"`
+}
+
+func decompressHandler(c *gin.Context) {
+	gzipReader, err := gzip.NewReader(c.Request.Body)
+	if err != nil {
+		decompressError(c, err)
+		return
+	}
+	defer gzipReader.Close()
+
+	// maxBodyBytes only bounds the compressed input; cap the output too so a
+	// small body can't inflate without limit.
+	text, err := io.ReadAll(http.MaxBytesReader(c.Writer, gzipReader, maxBodyBytes))
+	if err != nil {
+		decompressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, decompressResponse{Text: string(text)})
+}
+
+func decompressError(c *gin.Context, err error) {
+	if isBodyTooLarge(err) {
+		respondError(c, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+		return
+	}
+	respondError(c, http.StatusBadRequest, fmt.Errorf("invalid gzip data: %w", err))
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func postDecompress(r http.Handler, body []byte) *httptest.ResponseRecorder {
+	return serve(r, httptest.NewRequest(http.MethodPost, "/decompress", bytes.NewReader(body)))
+}
+
+func TestCompressDecompressRoundTrip(t *testing.T) {
+	r := newRouter()
+	compressed := postJSON(r, "/compress", `{"text":"round trip round trip round trip"}`)
+	if compressed.Code != http.StatusOK {
+		t.Fatalf("compress status = %d", compressed.Code)
+	}
+
+	w := postDecompress(r, compressed.Body.Bytes())
+	if w.Code != http.StatusOK {
+		t.Fatalf("decompress status = %d, body %s", w.Code, w.Body)
+	}
+	if got := decodeBody(t, w)["text"]; got != "round trip round trip round trip" {
+		t.Errorf("text = %q", got)
+	}
+}
+
+func TestDecompressTruncated(t *testing.T) {
+	data := gzipBytes(t, bytes.Repeat([]byte("truncate me "), 100))
+	w := postDecompress(newRouter(), data[:len(data)/2])
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestDecompressOutputLimit(t *testing.T) {
+	data := gzipBytes(t, make([]byte, maxBodyBytes+1))
+	if int64(len(data)) >= maxBodyBytes {
+		t.Fatalf("compressed input is %d bytes, want it under the body limit", len(data))
+	}
+	w := postDecompress(newRouter(), data)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
+
+func TestCompressNegotiation(t *testing.T) {
+	r := newRouter()
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/gzip"},
+		{"*/*", "application/gzip"},
+		{"application/gzip", "application/gzip"},
+		{"text/html", "application/gzip"},
+		{"application/json", "application/json; charset=utf-8"},
+		{"application/json, */*", "application/json; charset=utf-8"},
+		{"application/json;q=0.9", "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/compress", strings.NewReader(`{"text":"negotiate negotiate"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := serve(r, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Accept %q: status = %d", tt.accept, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tt.accept, got, tt.contentType)
+		}
+		if tt.contentType == "application/gzip" {
+			continue
+		}
+		encoded, _ := decodeBody(t, w)["compressed"].(string)
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+			t.Errorf("Accept %q: compressed is not base64 gzip: %q", tt.accept, encoded)
+		}
+	}
+}
+
+func TestCompressAlgorithmsRoundTrip(t *testing.T) {
+	const text = "deflate and zlib deflate and zlib deflate and zlib"
+	readers := map[string]func(io.Reader) (io.Reader, error){
+		"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil },
+		"zlib":    func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		"brotli":  func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	}
+	r := newRouter()
+	for algorithm, newReader := range readers {
+		w := postJSON(r, "/compress", `{"text":"`+text+`","algorithm":"`+algorithm+`"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", algorithm, w.Code, w.Body)
+		}
+		if got, want := w.Header().Get("Content-Type"), compressors[algorithm].contentType; got != want {
+			t.Errorf("%s: Content-Type = %q, want %q", algorithm, got, want)
+		}
+		zr, err := newReader(w.Body)
+		if err != nil {
+			t.Fatalf("%s: %v", algorithm, err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil || string(data) != text {
+			t.Errorf("%s: round trip = %q, %v", algorithm, data, err)
+		}
+	}
+}
+
+func TestCompressLevel(t *testing.T) {
+	text := strings.Repeat("brotli levels trade speed for size ", 200)
+	r := newRouter()
+	compress := func(level string) *httptest.ResponseRecorder {
+		body := `{"text":"` + text + `","algorithm":"brotli"`
+		if level != "" {
+			body += `,"level":` + level
+		}
+		return postJSON(r, "/compress", body+`}`)
+	}
+
+	for _, tt := range []struct{ level, want string }{
+		{"", strconv.Itoa(brotli.DefaultCompression)},
+		{"0", "0"},
+		{"11", "11"},
+	} {
+		w := compress(tt.level)
+		if w.Code != http.StatusOK {
+			t.Fatalf("level %q: status = %d, body %s", tt.level, w.Code, w.Body)
+		}
+		if got := w.Header().Get("X-Compression-Level"); got != tt.want {
+			t.Errorf("level %q: X-Compression-Level = %s, want %s", tt.level, got, tt.want)
+		}
+		data, err := io.ReadAll(brotli.NewReader(w.Body))
+		if err != nil || string(data) != text {
+			t.Errorf("level %q: round trip = %d bytes, %v", tt.level, len(data), err)
+		}
+	}
+
+	for _, level := range []string{"-1", "12"} {
+		w := compress(level)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("level %s: status = %d, want 400", level, w.Code)
+		}
+		if msg := errorMessage(decodeBody(t, w)); !strings.Contains(msg, "level must be between 0 and 11 for brotli") {
+			t.Errorf("level %s: message = %q", level, msg)
+		}
+	}
+}
+
+func compressionRatioHeader(t *testing.T, text string) float64 {
+	t.Helper()
+	w := postJSON(newRouter(), "/compress", `{"text":"`+text+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if got, want := w.Header().Get("X-Original-Bytes"), strconv.Itoa(len(text)); got != want {
+		t.Errorf("X-Original-Bytes = %s, want %s", got, want)
+	}
+	if got, want := w.Header().Get("X-Compressed-Bytes"), strconv.Itoa(w.Body.Len()); got != want {
+		t.Errorf("X-Compressed-Bytes = %s, want %s", got, want)
+	}
+	ratio, err := strconv.ParseFloat(w.Header().Get("X-Compression-Ratio"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ratio
+}
+
+func TestCompressionRatio(t *testing.T) {
+	if ratio := compressionRatioHeader(t, strings.Repeat("abcd", 1000)); ratio >= 0.1 {
+		t.Errorf("repetitive input ratio = %v, want well below 1", ratio)
+	}
+
+	random := make([]byte, 12)
+	rand.New(rand.NewSource(1)).Read(random)
+	if ratio := compressionRatioHeader(t, hex.EncodeToString(random)); ratio <= 1 {
+		t.Errorf("short random input ratio = %v, want above 1", ratio)
+	}
+}
+
+func TestCompressStream(t *testing.T) {
+	input := make([]byte, 4<<20)
+	rand.New(rand.NewSource(2)).Read(input[:len(input)/2])
+	if int64(len(input)) <= maxBodyBytes {
+		t.Fatalf("input of %d bytes doesn't exceed the body limit", len(input))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/compress/stream", bytes.NewReader(input))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	w := serve(newRouter(), req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %.200s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q", got)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, input) {
+		t.Errorf("decompressed %d bytes differ from the %d sent", len(output), len(input))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// rateTable maps a unit code to its value against a common base, so
+// converting from A to B multiplies by rates[B]/rates[A].
+type rateTable map[string]decimal.Decimal
+
+// conversionRates holds the table /convert reads; it is swapped whole on
+// reload so requests never see a partial table.
+var conversionRates atomic.Pointer[rateTable]
+
+// loadRateTable reads a JSON object of code to rate, e.g.
+// {"USD": 1, "EUR": "0.92"}. Codes are upper-cased and rates must be
+// positive.
+func loadRateTable(path string) (rateTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]decimal.Decimal
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	table := make(rateTable, len(raw))
+	for code, rate := range raw {
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("rate for %s must be positive", code)
+		}
+		table[strings.ToUpper(code)] = rate
+	}
+	return table, nil
+}
+
+// reloadRatesOnSignal reloads the table from path on every SIGHUP, keeping
+// the current table if the file is unreadable or invalid.
+func reloadRatesOnSignal(path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			table, err := loadRateTable(path)
+			if err != nil {
+				slog.Error("rate table reload failed", "path", path, "error", err)
+				continue
+			}
+			conversionRates.Store(&table)
+			slog.Info("rate table reloaded", "path", path, "codes", len(table))
+		}
+	}()
+}
+
+// lookupRate returns the rate for code, or a 404-worthy error if the
+// current table doesn't list it.
+func lookupRate(table rateTable, code string) (decimal.Decimal, error) {
+	rate, ok := table[code]
+	if !ok {
+		return decimal.Decimal{}, &APIError{
+			Code:    "unknown_code",
+			Message: "unknown code: " + code,
+			Details: map[string]interface{}{"code": code},
+		}
+	}
+	return rate, nil
+}
+
+type convertRequest struct {
+	From   string          `json:"from" binding:"required"`
+	To     string          `json:"to" binding:"required"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type convertResponse struct {
+	Amount decimal.Decimal `json:"amount"`
+	Rate   decimal.Decimal `json:"rate"`
+}
+
+func convertHandler(c *gin.Context) {
+	var payload convertRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	var table rateTable
+	if current := conversionRates.Load(); current != nil {
+		table = *current
+	}
+	from, err := lookupRate(table, strings.ToUpper(payload.From))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	to, err := lookupRate(table, strings.ToUpper(payload.To))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	rate := to.Div(from)
+	c.JSON(http.StatusOK, convertResponse{
+		Amount: payload.Amount.Mul(to).Div(from),
+		Rate:   rate,
+	})
+}
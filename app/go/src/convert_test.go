@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeRates writes a rate table file for loadRateTable.
+func writeRates(t *testing.T, path, rates string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(rates), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// useRatesFile loads path as the conversion table for the rest of the test.
+func useRatesFile(t *testing.T, path string) {
+	t.Helper()
+	table, err := loadRateTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := conversionRates.Load()
+	conversionRates.Store(&table)
+	t.Cleanup(func() { conversionRates.Store(previous) })
+}
+
+func TestConvert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	writeRates(t, path, `{"usd":1,"EUR":"0.92","JPY":150}`)
+	useRatesFile(t, path)
+	r := newRouter()
+
+	tests := []struct {
+		body, amount, rate string
+	}{
+		{`{"from":"USD","to":"EUR","amount":100}`, "92", "0.92"},
+		{`{"from":"usd","to":"jpy","amount":"2.5"}`, "375", "150"},
+		{`{"from":"EUR","to":"EUR","amount":"0.1"}`, "0.1", "1"},
+	}
+	for _, tt := range tests {
+		w := postJSON(r, "/convert", tt.body)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.body, w.Code, w.Body)
+			continue
+		}
+		body := decodeBody(t, w)
+		if body["amount"] != tt.amount || body["rate"] != tt.rate {
+			t.Errorf("%s: amount %v rate %v, want %s and %s", tt.body, body["amount"], body["rate"], tt.amount, tt.rate)
+		}
+	}
+
+	for _, body := range []string{
+		`{"from":"USD","to":"GBP","amount":1}`,
+		`{"from":"XXX","to":"EUR","amount":1}`,
+	} {
+		w := postJSON(r, "/convert", body)
+		if w.Code != http.StatusNotFound || errorCode(t, w) != "unknown_code" {
+			t.Errorf("%s: status = %d, body %s, want 404 unknown_code", body, w.Code, w.Body)
+		}
+	}
+}
+
+func TestConvertReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	writeRates(t, path, `{"USD":1,"EUR":"0.92"}`)
+	useRatesFile(t, path)
+	reloadRatesOnSignal(path)
+	r := newRouter()
+
+	rate := func() interface{} {
+		return decodeBody(t, postJSON(r, "/convert", `{"from":"USD","to":"EUR","amount":1}`))["rate"]
+	}
+	if got := rate(); got != "0.92" {
+		t.Fatalf("rate before reload = %v", got)
+	}
+
+	writeRates(t, path, `{"USD":1,"EUR":"0.95"}`)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for rate() != "0.95" {
+		if time.Now().After(deadline) {
+			t.Fatalf("rate after SIGHUP = %v, want 0.95", rate())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// An invalid file keeps the table already in use.
+	writeRates(t, path, `{"USD":1,"EUR":-1}`)
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	time.Sleep(50 * time.Millisecond)
+	if got := rate(); got != "0.95" {
+		t.Errorf("rate after invalid reload = %v, want 0.95 kept", got)
+	}
+}
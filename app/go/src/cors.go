@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsExposedHeaders are the custom response headers browsers may read;
+// without them a browser client can't see the timing data.
+var corsExposedHeaders = strings.Join([]string{
+	"X-Lambda-Start-Time",
+	"X-Lambda-End-Time",
+	"X-Lambda-Duration",
+	"X-Lambda-Duration-Ms",
+	"X-Cold-Start",
+	"X-Lazy-Init",
+	"X-Jitter-Ms",
+	requestIDHeader,
+}, ", ")
+
+// corsMiddleware answers cross-origin requests from the comma-separated
+// allowed origins, where "*" admits any origin, and short-circuits
+// preflight OPTIONS requests with 204.
+func corsMiddleware(allowedOrigins string) gin.HandlerFunc {
+	if allowedOrigins == "" {
+		allowedOrigins = "*"
+	}
+	allowed := make(map[string]bool)
+	for _, origin := range strings.Split(allowedOrigins, ",") {
+		allowed[strings.TrimSpace(origin)] = true
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		if allowed["*"] {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Writer.Header().Add("Vary", "Origin")
+			if !allowed[origin] {
+				c.Next()
+				return
+			}
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		c.Header("Access-Control-Expose-Headers", corsExposedHeaders)
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			if headers := c.GetHeader("Access-Control-Request-Headers"); headers != "" {
+				c.Header("Access-Control-Allow-Headers", headers)
+			}
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
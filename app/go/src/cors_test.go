@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func preflight(h http.Handler, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/math", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "content-type, x-cpu-burn-ms")
+	return serve(h, req)
+}
+
+func TestCORSPreflight(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://bench.example, https://other.example")
+	r := newRouter()
+
+	w := preflight(r, "https://bench.example")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", w.Code)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":  "https://bench.example",
+		"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
+		"Access-Control-Allow-Headers": "content-type, x-cpu-burn-ms",
+		"Access-Control-Max-Age":       "600",
+		"Vary":                         "Origin",
+	}
+	for header, value := range want {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+	exposed := w.Header().Get("Access-Control-Expose-Headers")
+	for _, header := range []string{"X-Lambda-Duration-Ms", "X-Cold-Start", requestIDHeader} {
+		if !strings.Contains(exposed, header) {
+			t.Errorf("Access-Control-Expose-Headers = %q, missing %s", exposed, header)
+		}
+	}
+
+	if w := preflight(r, "https://evil.example"); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed origin got Access-Control-Allow-Origin %q", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
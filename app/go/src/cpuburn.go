@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCPUBurn caps how long X-Cpu-Burn-Ms may spin a request.
+var maxCPUBurn = 5 * time.Second
+
+// burnSink keeps the spin loop's work observable so it isn't optimized away.
+var burnSink uint64
+
+// cpuBurnMiddleware busy-loops for the duration in X-Cpu-Burn-Ms before the
+// handler runs, consuming CPU rather than sleeping.
+func cpuBurnMiddleware(c *gin.Context) {
+	header := c.GetHeader("X-Cpu-Burn-Ms")
+	if header == "" {
+		c.Next()
+		return
+	}
+	ms, err := strconv.Atoi(header)
+	if err != nil || ms < 0 || time.Duration(ms)*time.Millisecond > maxCPUBurn {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("X-Cpu-Burn-Ms must be between 0 and %d", maxCPUBurn.Milliseconds()))
+		return
+	}
+	burnCPU(time.Duration(ms) * time.Millisecond)
+	c.Next()
+}
+
+func burnCPU(d time.Duration) {
+	deadline := time.Now().Add(d)
+	var x uint64
+	for time.Now().Before(deadline) {
+		for i := 0; i < 1000; i++ {
+			x = x*6364136223846793005 + 1442695040888963407
+		}
+	}
+	atomic.AddUint64(&burnSink, x)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCPUBurnLatency(t *testing.T) {
+	r := newRouter()
+	burned := func(header string) (*httptest.ResponseRecorder, time.Duration) {
+		req := httptest.NewRequest(http.MethodPost, "/math", strings.NewReader(`{"operation":"sum","numbers":[1]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Cpu-Burn-Ms", header)
+		start := time.Now()
+		w := serve(r, req)
+		return w, time.Since(start)
+	}
+
+	w, elapsed := burned("100")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if elapsed < 100*time.Millisecond || elapsed > 300*time.Millisecond {
+		t.Errorf("request with a 100ms burn took %v", elapsed)
+	}
+
+	for _, header := range []string{"abc", "-1", "5001"} {
+		if w, _ := burned(header); w.Code != http.StatusBadRequest {
+			t.Errorf("X-Cpu-Burn-Ms %s: status = %d, want 400", header, w.Code)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memStatsResponse struct {
+	Alloc        uint64 `json:"alloc"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+	Sys          uint64 `json:"sys"`
+	TotalAlloc   uint64 `json:"total_alloc"`
+}
+
+func memStatsHandler(c *gin.Context) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	c.JSON(http.StatusOK, memStatsResponse{
+		Alloc:        m.Alloc,
+		HeapObjects:  m.HeapObjects,
+		NumGC:        m.NumGC,
+		PauseTotalNs: m.PauseTotalNs,
+		Sys:          m.Sys,
+		TotalAlloc:   m.TotalAlloc,
+	})
+}
+
+type gcResponse struct {
+	DurationMs float64 `json:"duration_ms"`
+	FreedBytes uint64  `json:"freed_bytes"`
+	NumGC      uint32  `json:"num_gc"`
+	PauseNs    uint64  `json:"pause_ns"`
+}
+
+// gcHandler forces a collection and reports what it freed and how long the
+// world was stopped.
+func gcHandler(c *gin.Context) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	runtime.GC()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	var freed uint64
+	if before.HeapAlloc > after.HeapAlloc {
+		freed = before.HeapAlloc - after.HeapAlloc
+	}
+	c.JSON(http.StatusOK, gcResponse{
+		DurationMs: float64(elapsed.Microseconds()) / 1000,
+		FreedBytes: freed,
+		NumGC:      after.NumGC - before.NumGC,
+		PauseNs:    after.PauseTotalNs - before.PauseTotalNs,
+	})
+}
+
+// registerPprof mounts the net/http/pprof handlers under /debug/pprof, along
+// with /debug/gc. It is only called when ENABLE_PPROF=true.
+func registerPprof(r *gin.Engine) {
+	r.POST("/debug/gc", gcHandler)
+	g := r.Group("/debug/pprof")
+	g.GET("/", gin.WrapF(pprof.Index))
+	g.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	g.GET("/profile", gin.WrapF(pprof.Profile))
+	g.GET("/symbol", gin.WrapF(pprof.Symbol))
+	g.POST("/symbol", gin.WrapF(pprof.Symbol))
+	g.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		g.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMemStats(t *testing.T) {
+	w := serve(newRouter(), httptest.NewRequest(http.MethodGet, "/debug/memstats", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	body := decodeBody(t, w)
+	for _, key := range []string{"alloc", "total_alloc", "sys", "num_gc", "heap_objects", "pause_total_ns"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("missing %q in %v", key, body)
+		}
+	}
+	if alloc, _ := body["alloc"].(float64); alloc <= 0 {
+		t.Errorf("alloc = %v, want > 0", body["alloc"])
+	}
+}
+
+func TestPprofFlag(t *testing.T) {
+	for _, tt := range []struct {
+		env  string
+		want int
+	}{
+		{"", http.StatusNotFound},
+		{"true", http.StatusOK},
+	} {
+		t.Setenv("ENABLE_PPROF", tt.env)
+		w := serve(newRouter(), httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+		if w.Code != tt.want {
+			t.Errorf("ENABLE_PPROF=%q: status = %d, want %d", tt.env, w.Code, tt.want)
+		}
+	}
+}
+
+func TestDebugGC(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	r := newRouter()
+	if w := postJSON(r, "/memory", `{"mb":32,"touch":true}`); w.Code != http.StatusOK {
+		t.Fatalf("/memory status = %d, body %s", w.Code, w.Body)
+	}
+
+	w := serve(r, httptest.NewRequest(http.MethodPost, "/debug/gc", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	body := decodeBody(t, w)
+	if numGC, _ := body["num_gc"].(float64); numGC < 1 {
+		t.Errorf("num_gc = %v, want at least 1", body["num_gc"])
+	}
+	if pause, _ := body["pause_ns"].(float64); pause <= 0 {
+		t.Errorf("pause_ns = %v, want > 0", body["pause_ns"])
+	}
+	if _, ok := body["freed_bytes"]; !ok {
+		t.Errorf("freed_bytes missing from %v", body)
+	}
+
+	t.Setenv("ENABLE_PPROF", "")
+	if w := serve(newRouter(), httptest.NewRequest(http.MethodPost, "/debug/gc", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("pprof disabled: status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxEchoBodyBytes caps how much of the request body /echo reflects back.
+const maxEchoBodyBytes = 64 << 10
+
+type echoResponse struct {
+	Body          string      `json:"body"`
+	BodyBytes     int         `json:"body_bytes"`
+	BodyTruncated bool        `json:"body_truncated"`
+	Headers       http.Header `json:"headers"`
+	Method        string      `json:"method"`
+	Path          string      `json:"path"`
+	Query         url.Values  `json:"query"`
+}
+
+// echoHandler reflects the request it received, so a load tool's output can
+// be checked against what was intended.
+func echoHandler(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		if isBodyTooLarge(err) {
+			respondError(c, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+			return
+		}
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, echoResponse{
+		Body:          string(body[:min(len(body), maxEchoBodyBytes)]),
+		BodyBytes:     len(body),
+		BodyTruncated: len(body) > maxEchoBodyBytes,
+		Headers:       c.Request.Header,
+		Method:        c.Request.Method,
+		Path:          c.Request.URL.Path,
+		Query:         c.Request.URL.Query(),
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEcho(t *testing.T) {
+	r := newRouter()
+	req := httptest.NewRequest(http.MethodPost, "/echo?a=1&a=2&b=x", strings.NewReader("hello body"))
+	req.Header.Set("X-Bench-Run", "42")
+	req.Header.Add("X-Multi", "one")
+	req.Header.Add("X-Multi", "two")
+
+	w := serve(r, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var got echoResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != http.MethodPost || got.Path != "/echo" {
+		t.Errorf("method %q path %q", got.Method, got.Path)
+	}
+	if got.Body != "hello body" || got.BodyBytes != 10 || got.BodyTruncated {
+		t.Errorf("body %q, %d bytes, truncated %t", got.Body, got.BodyBytes, got.BodyTruncated)
+	}
+	if v := got.Headers.Get("X-Bench-Run"); v != "42" {
+		t.Errorf("X-Bench-Run = %q", v)
+	}
+	if v := got.Headers.Values("X-Multi"); !reflect.DeepEqual(v, []string{"one", "two"}) {
+		t.Errorf("X-Multi = %v", v)
+	}
+	if !reflect.DeepEqual(got.Query["a"], []string{"1", "2"}) || got.Query.Get("b") != "x" {
+		t.Errorf("query = %v", got.Query)
+	}
+}
+
+func TestEchoTruncatesBody(t *testing.T) {
+	body := strings.Repeat("x", maxEchoBodyBytes+10)
+	w := serve(newRouter(), httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var got echoResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Body) != maxEchoBodyBytes || got.BodyBytes != len(body) || !got.BodyTruncated {
+		t.Errorf("echoed %d of %d bytes, truncated %t", len(got.Body), got.BodyBytes, got.BodyTruncated)
+	}
+}
+
+func TestEchoBodyLimit(t *testing.T) {
+	withMaxBodyBytes(t, 100)
+	w := serve(newRouter(), httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(strings.Repeat("x", 101))))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
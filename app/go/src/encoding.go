@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// codec is one text encoding /encode and /decode support.
+type codec struct {
+	encode func([]byte) string
+	decode func(string) ([]byte, error)
+}
+
+var codecs = map[string]codec{
+	"base64": {base64.StdEncoding.EncodeToString, base64.StdEncoding.DecodeString},
+	"base32": {base32.StdEncoding.EncodeToString, base32.StdEncoding.DecodeString},
+	"hex":    {hex.EncodeToString, hex.DecodeString},
+}
+
+type codecRequest struct {
+	Data     string `json:"data"`
+	Encoding string `json:"encoding" binding:"required"`
+}
+
+// bindCodec binds the request and resolves its codec, writing a 400 and
+// returning false on failure.
+func bindCodec(c *gin.Context) (codecRequest, codec, bool) {
+	var payload codecRequest
+	if !bindJSON(c, &payload) {
+		return payload, codec{}, false
+	}
+	cd, ok := codecs[payload.Encoding]
+	if !ok {
+		respondError(c, http.StatusBadRequest, newAPIError("unsupported_encoding", "unsupported encoding: "+payload.Encoding))
+		return payload, codec{}, false
+	}
+	return payload, cd, true
+}
+
+type encodeResponse struct {
+	Encoded string `json:"encoded"`
+}
+
+func encodeHandler(c *gin.Context) {
+	payload, cd, ok := bindCodec(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, encodeResponse{Encoded: cd.encode([]byte(payload.Data))})
+}
+
+type decodeResponse struct {
+	BytesLen int    `json:"bytes_len"`
+	Text     string `json:"text"`
+}
+
+func decodeHandler(c *gin.Context) {
+	payload, cd, ok := bindCodec(c)
+	if !ok {
+		return
+	}
+	data, err := cd.decode(payload.Data)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("invalid %s data: %w", payload.Encoding, err))
+		return
+	}
+	c.JSON(http.StatusOK, decodeResponse{BytesLen: len(data), Text: string(data)})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	r := newRouter()
+	const text = "héllo, wörld ✓"
+	for encoding := range codecs {
+		w := postJSON(r, "/encode", fmt.Sprintf(`{"encoding":%q,"data":%q}`, encoding, text))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s encode: status = %d, body %s", encoding, w.Code, w.Body)
+		}
+		encoded, _ := decodeBody(t, w)["encoded"].(string)
+
+		w = postJSON(r, "/decode", fmt.Sprintf(`{"encoding":%q,"data":%q}`, encoding, encoded))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s decode: status = %d, body %s", encoding, w.Code, w.Body)
+		}
+		body := decodeBody(t, w)
+		if got := body["text"]; got != text {
+			t.Errorf("%s: text = %q", encoding, got)
+		}
+		if got := body["bytes_len"]; got != float64(len(text)) {
+			t.Errorf("%s: bytes_len = %v, want %d", encoding, got, len(text))
+		}
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	r := newRouter()
+	for encoding, data := range map[string]string{
+		"base64": "not*base64",
+		"base32": "abc",
+		"hex":    "zz",
+	} {
+		w := postJSON(r, "/decode", fmt.Sprintf(`{"encoding":%q,"data":%q}`, encoding, data))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s %q: status = %d, want 400", encoding, data, w.Code)
+		}
+	}
+	if w := postJSON(r, "/encode", `{"encoding":"rot13","data":"x"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown encoding: status = %d, want 400", w.Code)
+	}
+}
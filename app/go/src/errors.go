@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIError is the body of every error response, sent as
+// {"error":{"code":...,"message":...,"details":...}}. Code is a stable
+// machine-readable identifier; Message is for humans and may change.
+type APIError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// errorResponse is the envelope every error is sent in.
+type errorResponse struct {
+	Error *APIError `json:"error"`
+}
+
+func newAPIError(code, message string) *APIError {
+	return &APIError{Code: code, Message: message}
+}
+
+var errBodyTooLarge = newAPIError("body_too_large", "request body too large")
+
+// statusCodes are the codes used for errors that aren't already an
+// *APIError.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            "invalid_request",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusRequestEntityTooLarge: "body_too_large",
+	http.StatusTooManyRequests:       "rate_limited",
+	statusClientClosedRequest:        "request_canceled",
+	http.StatusInternalServerError:   "internal_error",
+	http.StatusBadGateway:            "upstream_error",
+	http.StatusServiceUnavailable:    "unavailable",
+	http.StatusGatewayTimeout:        "timeout",
+}
+
+// respondError aborts the request with status and err in the error
+// envelope. An *APIError anywhere in err's chain is sent as-is; any other
+// error gets a code derived from status.
+func respondError(c *gin.Context, status int, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		code, ok := statusCodes[status]
+		if !ok {
+			code = "error"
+		}
+		apiErr = newAPIError(code, err.Error())
+	}
+	c.AbortWithStatusJSON(status, errorResponse{Error: apiErr})
+}
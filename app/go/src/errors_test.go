@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// decodeEnvelope decodes an error response, failing unless it holds exactly
+// the "error" key with a code and message.
+func decodeEnvelope(t *testing.T, w *httptest.ResponseRecorder) map[string]json.RawMessage {
+	t.Helper()
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("body is not JSON: %v: %s", err, w.Body)
+	}
+	if len(envelope) != 1 || envelope["error"] == nil {
+		t.Fatalf("body %s is not {\"error\":...}", w.Body)
+	}
+	var apiErr map[string]json.RawMessage
+	if err := json.Unmarshal(envelope["error"], &apiErr); err != nil {
+		t.Fatalf("error is not an object: %s", w.Body)
+	}
+	for key := range apiErr {
+		if key != "code" && key != "message" && key != "details" {
+			t.Errorf("unexpected key %q in %s", key, w.Body)
+		}
+	}
+	if apiErr["code"] == nil || apiErr["message"] == nil {
+		t.Errorf("code or message missing from %s", w.Body)
+	}
+	return apiErr
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	r := newRouter()
+	tests := []struct {
+		path, body  string
+		status      int
+		code        string
+		wantDetails bool
+	}{
+		{"/math", `{"operation":"cube","numbers":[1]}`, http.StatusBadRequest, "invalid_operation", true},
+		{"/math", `{"numbers":[1]}`, http.StatusBadRequest, "validation_failed", true},
+		{"/math", `{"operation":`, http.StatusBadRequest, "invalid_request", false},
+		{"/hash", `{"algorithm":"crc32"}`, http.StatusBadRequest, "unsupported_algorithm", false},
+		{"/string", `{"text":"a","pattern":"("}`, http.StatusBadRequest, "invalid_pattern", false},
+	}
+	for _, tt := range tests {
+		w := postJSON(r, tt.path, tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.path, tt.body, w.Code, tt.status)
+			continue
+		}
+		apiErr := decodeEnvelope(t, w)
+		var code string
+		json.Unmarshal(apiErr["code"], &code)
+		if code != tt.code {
+			t.Errorf("%s %s: code = %q, want %q", tt.path, tt.body, code, tt.code)
+		}
+		if hasDetails := apiErr["details"] != nil; hasDetails != tt.wantDetails {
+			t.Errorf("%s %s: details present = %t, want %t", tt.path, tt.body, hasDetails, tt.wantDetails)
+		}
+	}
+}
+
+func TestRespondError(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+		code   string
+	}{
+		{http.StatusBadGateway, errors.New("boom"), "upstream_error"},
+		{http.StatusTeapot, errors.New("boom"), "error"},
+		{http.StatusInternalServerError, fmt.Errorf("wrapped: %w", newAPIError("custom", "inner")), "custom"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		respondError(c, tt.status, tt.err)
+		if w.Code != tt.status {
+			t.Errorf("%v: status = %d, want %d", tt.err, w.Code, tt.status)
+		}
+		decodeEnvelope(t, w)
+		if code := errorCode(t, w); code != tt.code {
+			t.Errorf("%v: code = %q, want %q", tt.err, code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagRoutes respond purely as a function of their request, so a tag
+// derived from the request identifies the response too.
+var etagRoutes = map[string]bool{
+	"/math":            true,
+	"/math/expression": true,
+	"/math/matrix":     true,
+	"/math/vector":     true,
+	"/compress":        true,
+	"/json":            true,
+	"/json/echo":       true,
+}
+
+// requestETag hashes the route, the Accept header (which selects the
+// response encoding on some routes) and the body.
+func requestETag(route, accept string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, route)
+	h.Write([]byte{0})
+	io.WriteString(h, accept)
+	h.Write([]byte{0})
+	h.Write(body)
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists tag or "*".
+func etagMatches(ifNoneMatch, tag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter sets the ETag header right before the response header is sent,
+// and only for a 2xx status: an error says nothing about the representation
+// a client could cache. When the client already holds the tag, the response
+// is held back instead, so the middleware can turn it into a 304 once it
+// knows the handler succeeded.
+type etagWriter struct {
+	gin.ResponseWriter
+	tag         string
+	conditional bool
+	held        bool
+	body        bytes.Buffer
+}
+
+func successful(status int) bool {
+	return status >= 200 && status < 300
+}
+
+func (w *etagWriter) tagIfSuccessful() {
+	if !w.ResponseWriter.Written() && successful(w.Status()) {
+		w.Header().Set("ETag", w.tag)
+	}
+}
+
+func (w *etagWriter) WriteHeaderNow() {
+	if w.conditional {
+		w.held = true
+		return
+	}
+	w.tagIfSuccessful()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if w.conditional {
+		w.held = true
+		return w.body.Write(data)
+	}
+	w.tagIfSuccessful()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	if w.conditional {
+		w.held = true
+		return w.body.WriteString(s)
+	}
+	w.tagIfSuccessful()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *etagWriter) Flush() {
+	if w.conditional {
+		return
+	}
+	w.tagIfSuccessful()
+	w.ResponseWriter.Flush()
+}
+
+// Written counts a held response as written, as gzipResponseWriter does.
+func (w *etagWriter) Written() bool {
+	if w.conditional {
+		return w.held
+	}
+	return w.ResponseWriter.Written()
+}
+
+// release sends a held response: an empty 304 when the handler succeeded,
+// otherwise the handler's own status and body, untagged.
+func (w *etagWriter) release() {
+	if !successful(w.Status()) {
+		w.ResponseWriter.WriteHeaderNow()
+		w.ResponseWriter.Write(w.body.Bytes())
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Set("ETag", w.tag)
+	w.ResponseWriter.WriteHeader(http.StatusNotModified)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// etagMiddleware tags 2xx responses on etagRoutes and answers 304 when the
+// client already holds the tag and the handler succeeds again. It is enabled
+// with ENABLE_ETAG=true.
+func etagMiddleware(c *gin.Context) {
+	route := c.FullPath()
+	if !etagRoutes[route] {
+		c.Next()
+		return
+	}
+
+	body, ok := bufferBody(c)
+	if !ok {
+		return
+	}
+
+	tag := requestETag(route, c.GetHeader("Accept"), body)
+	w := &etagWriter{
+		ResponseWriter: c.Writer,
+		tag:            tag,
+		conditional:    etagMatches(c.GetHeader("If-None-Match"), tag),
+	}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+	if w.conditional {
+		w.release()
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETagNotModified(t *testing.T) {
+	t.Setenv("ENABLE_ETAG", "true")
+	r := newRouter()
+	body := `{"operation":"sum","numbers":[1,2,3]}`
+
+	first := postJSON(r, "/math", body)
+	if first.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", first.Code, first.Body)
+	}
+	tag := first.Header().Get("ETag")
+	if tag == "" {
+		t.Fatal("ETag header missing")
+	}
+
+	repeat := func(body, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/math", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		return serve(r, req)
+	}
+	if w := repeat(body, tag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("matching tag: status = %d, body %q, want empty 304", w.Code, w.Body)
+	}
+	if w := repeat(`{"operation":"sum","numbers":[1,2,4]}`, tag); w.Code != http.StatusOK {
+		t.Errorf("different body: status = %d, want 200", w.Code)
+	}
+}
+
+func TestETagOnlyOnSuccess(t *testing.T) {
+	t.Setenv("ENABLE_ETAG", "true")
+	r := newRouter()
+	body := `{"operation":"sum","numbers":[]}`
+
+	w := postJSON(r, "/math", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("ETag"); got != "" {
+		t.Errorf("400 carries ETag %q", got)
+	}
+
+	// Even a client that guesses the tag, or sends "*", gets the error back
+	// rather than a 304 telling it to reuse a cached error.
+	tag := requestETag("/math", "", []byte(body))
+	for _, ifNoneMatch := range []string{tag, "*"} {
+		req := httptest.NewRequest(http.MethodPost, "/math", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := serve(r, req)
+		if w.Code != http.StatusBadRequest || errorCode(t, w) == "" {
+			t.Errorf("If-None-Match %s: status = %d, body %q, want the 400", ifNoneMatch, w.Code, w.Body)
+		}
+		if got := w.Header().Get("ETag"); got != "" {
+			t.Errorf("If-None-Match %s: 400 carries ETag %q", ifNoneMatch, got)
+		}
+	}
+}
+
+func TestETagDisabled(t *testing.T) {
+	w := postJSON(newRouter(), "/math", `{"operation":"sum","numbers":[1]}`)
+	if got := w.Header().Get("ETag"); got != "" {
+		t.Errorf("ETag = %q without ENABLE_ETAG", got)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFibonacciN caps the n accepted by /fibonacci.
+var maxFibonacciN = 100000
+
+type fibonacciRequest struct {
+	N int `json:"n"`
+}
+
+type fibonacciResponse struct {
+	Result string `json:"result"`
+}
+
+func fibonacciHandler(c *gin.Context) {
+	var payload fibonacciRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if payload.N < 0 || payload.N > maxFibonacciN {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("n must be between 0 and %d", maxFibonacciN))
+		return
+	}
+
+	result, err := fibonacci(c.Request.Context(), payload.N)
+	if err != nil {
+		respondIfCanceled(c)
+		return
+	}
+	c.JSON(http.StatusOK, fibonacciResponse{Result: result.String()})
+}
+
+// fibonacci computes F(n) iteratively with big.Int so large n stays exact.
+// It polls ctx periodically and returns its error if the request goes away.
+func fibonacci(ctx context.Context, n int) (*big.Int, error) {
+	a, b := big.NewInt(0), big.NewInt(1)
+	for i := 0; i < n; i++ {
+		if i%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		a.Add(a, b)
+		a, b = b, a
+	}
+	return a, nil
+}
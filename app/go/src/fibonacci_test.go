@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFibonacci(t *testing.T) {
+	w := postJSON(newRouter(), "/fibonacci", `{"n":90}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if got := decodeBody(t, w)["result"]; got != "2880067194370816120" {
+		t.Errorf("result = %v", got)
+	}
+}
+
+func TestFibonacciCanceledMidRequest(t *testing.T) {
+	defer func(n int) { maxFibonacciN = n }(maxFibonacciN)
+	maxFibonacciN = 100000000
+
+	w, elapsed := postCanceled(t, "/fibonacci", `{"n":100000000}`, 50*time.Millisecond)
+	if w.Code != statusClientClosedRequest {
+		t.Errorf("status = %d, want %d", w.Code, statusClientClosedRequest)
+	}
+	if elapsed > time.Second {
+		t.Errorf("handler returned after %v, want shortly after the cancel", elapsed)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipMinBytes is the smallest response worth compressing.
+const gzipMinBytes = 1 << 10
+
+// gzipExcludedRoutes already produce binary or self-encoded bodies.
+var gzipExcludedRoutes = map[string]bool{
+	"/compress":        true,
+	"/compress/stream": true,
+	"/image":           true,
+	"/metrics":         true,
+}
+
+// gzipResponseWriter buffers the body so the size is known before choosing
+// whether to compress. A Flush switches it to pass-through, since streamed
+// responses can't wait for the whole body.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	buf           bytes.Buffer
+	passthrough   bool
+	headerWritten bool
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buf.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.buf.WriteString(s)
+}
+
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.headerWritten = true
+}
+
+// Written counts a buffered response as written, so middleware that only
+// responds when the handler didn't (timeouts, recovery) doesn't append a
+// second body to one still waiting in the buffer.
+func (w *gzipResponseWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.headerWritten || w.buf.Len() > 0
+}
+
+func (w *gzipResponseWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	if !w.Written() {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if !w.passthrough {
+		w.passthrough = true
+		w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish writes the buffered body, gzipped if it is large enough.
+func (w *gzipResponseWriter) finish() {
+	if w.passthrough {
+		return
+	}
+	if w.buf.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	if w.buf.Len() < gzipMinBytes || w.Header().Get("Content-Encoding") != "" {
+		w.ResponseWriter.Write(w.buf.Bytes())
+		return
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	gz := gzip.NewWriter(w.ResponseWriter)
+	gz.Write(w.buf.Bytes())
+	gz.Close()
+}
+
+// gzipResponseMiddleware compresses responses of at least gzipMinBytes for
+// clients that send Accept-Encoding: gzip.
+func gzipResponseMiddleware(c *gin.Context) {
+	if gzipExcludedRoutes[c.FullPath()] {
+		c.Next()
+		return
+	}
+	c.Writer.Header().Add("Vary", "Accept-Encoding")
+	if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		c.Next()
+		return
+	}
+
+	original := c.Writer
+	gw := &gzipResponseWriter{ResponseWriter: original}
+	c.Writer = gw
+	// Restoring the writer even on panic lets the recovery middleware reply
+	// directly instead of into a buffer nobody flushes.
+	defer func() { c.Writer = original }()
+
+	c.Next()
+	gw.finish()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func postAcceptingGzip(r http.Handler, path, body string, gzipped bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if gzipped {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	return serve(r, req)
+}
+
+// responseBody returns the body, gunzipping it if it was compressed.
+func responseBody(t *testing.T, w *httptest.ResponseRecorder) []byte {
+	t.Helper()
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		return w.Body.Bytes()
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGzipResponse(t *testing.T) {
+	r := newRouter()
+	tests := []struct {
+		name         string
+		body         string
+		acceptGzip   bool
+		wantEncoding string
+	}{
+		{"large with gzip", `{"count":500}`, true, "gzip"},
+		{"large without gzip", `{"count":500}`, false, ""},
+		{"small with gzip", `{"count":1}`, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postAcceptingGzip(r, "/random", tt.body, tt.acceptGzip)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d", w.Code)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if !json.Valid(responseBody(t, w)) {
+				t.Error("body is not valid JSON")
+			}
+		})
+	}
+}
+
+// A handler that responds after its deadline has passed must still get a
+// single clean response when the body is buffered for compression.
+func TestGzipResponseAfterDeadline(t *testing.T) {
+	saved := routeTimeouts
+	routeTimeouts = map[string]time.Duration{"/hash": 20 * time.Millisecond, "/random": 20 * time.Millisecond}
+	defer func() { routeTimeouts = saved }()
+	r := newRouter()
+
+	for _, path := range []string{"/hash", "/random"} {
+		for _, acceptGzip := range []bool{false, true} {
+			body := `{"text":"late","algorithm":"sha256"}`
+			if path == "/random" {
+				body = `{"count":500}`
+			}
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Cpu-Burn-Ms", "50")
+			if acceptGzip {
+				req.Header.Set("Accept-Encoding", "gzip")
+			}
+			w := serve(r, req)
+			if w.Code != http.StatusOK {
+				t.Errorf("%s gzip=%t: status = %d, want 200", path, acceptGzip, w.Code)
+			}
+			if data := responseBody(t, w); !json.Valid(data) {
+				t.Errorf("%s gzip=%t: body is not a single JSON value", path, acceptGzip)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hashInput returns the bytes to digest: text itself, or its decoding when
+// the client sent binary data as base64.
+func hashInput(text string, isBase64 bool) ([]byte, error) {
+	if isBase64 {
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
+
+type hashRequest struct {
+	Text      string `json:"text"`
+	Algorithm string `json:"algorithm" binding:"required"`
+	Base64    bool   `json:"base64"`
+}
+
+type hashResponse struct {
+	Hash string `json:"hash"`
+}
+
+func hashHandler(c *gin.Context) {
+	var payload hashRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	newHash, ok := hashAlgorithms[payload.Algorithm]
+	if !ok {
+		respondError(c, http.StatusBadRequest, newAPIError("unsupported_algorithm", "unsupported algorithm: "+payload.Algorithm))
+		return
+	}
+	data, err := hashInput(payload.Text, payload.Base64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("invalid base64 text: %w", err))
+		return
+	}
+
+	h := newHash()
+	h.Write(data)
+	c.JSON(http.StatusOK, hashResponse{Hash: hex.EncodeToString(h.Sum(nil))})
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHashVectors(t *testing.T) {
+	r := newRouter()
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"algorithm":"md5","text":""}`, "d41d8cd98f00b204e9800998ecf8427e"},
+		{`{"algorithm":"sha1","text":"abc"}`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{`{"algorithm":"sha256","text":""}`, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{`{"algorithm":"sha256","text":"abc"}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`{"algorithm":"sha512","text":"abc"}`, "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{`{"algorithm":"sha256","text":"YWJj","base64":true}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, tt := range tests {
+		w := postJSON(r, "/hash", tt.body)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.body, w.Code, w.Body)
+			continue
+		}
+		if got := decodeBody(t, w)["hash"]; got != tt.want {
+			t.Errorf("%s: hash = %v, want %s", tt.body, got, tt.want)
+		}
+	}
+
+	for _, body := range []string{
+		`{"algorithm":"crc32","text":"abc"}`,
+		`{"algorithm":"sha256","text":"not base64!","base64":true}`,
+	} {
+		if w := postJSON(r, "/hash", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
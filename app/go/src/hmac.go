@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type hmacRequest struct {
+	Text      string `json:"text"`
+	Key       string `json:"key" binding:"required"`
+	Algorithm string `json:"algorithm" binding:"required"`
+}
+
+// computeHMAC writes a 400 and returns false for an unknown algorithm.
+func computeHMAC(c *gin.Context, req hmacRequest) ([]byte, bool) {
+	newHash, ok := hashAlgorithms[req.Algorithm]
+	if !ok {
+		respondError(c, http.StatusBadRequest, newAPIError("unsupported_algorithm", "unsupported algorithm: "+req.Algorithm))
+		return nil, false
+	}
+	mac := hmac.New(newHash, []byte(req.Key))
+	mac.Write([]byte(req.Text))
+	return mac.Sum(nil), true
+}
+
+type hmacSignResponse struct {
+	MAC string `json:"mac"`
+}
+
+func hmacSignHandler(c *gin.Context) {
+	var payload hmacRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	sum, ok := computeHMAC(c, payload)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, hmacSignResponse{MAC: hex.EncodeToString(sum)})
+}
+
+type hmacVerifyRequest struct {
+	hmacRequest
+	MAC string `json:"mac" binding:"required"`
+}
+
+type hmacVerifyResponse struct {
+	Valid bool `json:"valid"`
+}
+
+func hmacVerifyHandler(c *gin.Context) {
+	var payload hmacVerifyRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	expected, err := hex.DecodeString(payload.MAC)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("invalid mac: %w", err))
+		return
+	}
+	sum, ok := computeHMAC(c, payload.hmacRequest)
+	if !ok {
+		return
+	}
+	// hmac.Equal compares in constant time so the response latency doesn't
+	// leak how much of a forged MAC was correct.
+	c.JSON(http.StatusOK, hmacVerifyResponse{Valid: hmac.Equal(sum, expected)})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHMAC(t *testing.T) {
+	r := newRouter()
+	// RFC 4231 test case 2 and RFC 2104's MD5 example share this key and text.
+	const text = "what do ya want for nothing?"
+	vectors := map[string]string{
+		"sha256": "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		"md5":    "750c783e6ab0b503eaa86e310a5db738",
+	}
+	for algorithm, want := range vectors {
+		w := postJSON(r, "/hmac/sign", fmt.Sprintf(`{"algorithm":%q,"key":"Jefe","text":%q}`, algorithm, text))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", algorithm, w.Code, w.Body)
+		}
+		if got := decodeBody(t, w)["mac"]; got != want {
+			t.Errorf("%s: mac = %v, want %s", algorithm, got, want)
+		}
+	}
+
+	verify := func(key, text, mac string) interface{} {
+		t.Helper()
+		w := postJSON(r, "/hmac/verify", fmt.Sprintf(`{"algorithm":"sha256","key":%q,"text":%q,"mac":%q}`, key, text, mac))
+		if w.Code != http.StatusOK {
+			t.Fatalf("verify: status = %d, body %s", w.Code, w.Body)
+		}
+		return decodeBody(t, w)["valid"]
+	}
+	mac := vectors["sha256"]
+	if got := verify("Jefe", text, mac); got != true {
+		t.Errorf("genuine mac: valid = %v", got)
+	}
+	if got := verify("Jefe", text+"!", mac); got != false {
+		t.Errorf("tampered text: valid = %v", got)
+	}
+	if got := verify("jefe", text, mac); got != false {
+		t.Errorf("wrong key: valid = %v", got)
+	}
+	if got := verify("Jefe", text, "00"+mac[2:]); got != false {
+		t.Errorf("tampered mac: valid = %v", got)
+	}
+
+	for path, body := range map[string]string{
+		"/hmac/sign":   `{"algorithm":"sha3","key":"k","text":"t"}`,
+		"/hmac/verify": `{"algorithm":"sha256","key":"k","text":"t","mac":"not hex"}`,
+	} {
+		if w := postJSON(r, path, body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: status = %d, want 400", path, body, w.Code)
+		}
+	}
+}
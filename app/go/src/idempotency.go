@@ -0,0 +1,175 @@
+package main
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyEntry is the response stored for a key, or a reservation while
+// the first request carrying the key is still running.
+type idempotencyEntry struct {
+	key         string
+	fingerprint string
+	expires     time.Time
+	response    *sharedResponse // nil while the request is in flight
+	header      http.Header     // what the handler set, restored on replay
+}
+
+// idempotencyStore keeps up to maxEntries responses for ttl each. Entries
+// share one TTL, so insertion order is also expiry order and the oldest entry
+// is evicted first when the store is full.
+type idempotencyStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+func newIdempotencyStore(ttl time.Duration, maxEntries int) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// begin returns a copy of the live entry for key, or reserves key for the
+// caller and reports reserved if there is none.
+func (s *idempotencyStore) begin(key, fingerprint string, now time.Time) (entry idempotencyEntry, reserved bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for front := s.order.Front(); front != nil && !now.Before(front.Value.(*idempotencyEntry).expires); front = s.order.Front() {
+		s.remove(front)
+	}
+	if element, ok := s.entries[key]; ok {
+		return *element.Value.(*idempotencyEntry), false
+	}
+	if s.order.Len() >= s.maxEntries {
+		s.remove(s.order.Front())
+	}
+	s.entries[key] = s.order.PushBack(&idempotencyEntry{key: key, fingerprint: fingerprint, expires: now.Add(s.ttl)})
+	return idempotencyEntry{}, true
+}
+
+// finish stores the response for a key reserved by begin, unless it has
+// since been evicted.
+func (s *idempotencyStore) finish(key string, response *sharedResponse, header http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok {
+		entry := element.Value.(*idempotencyEntry)
+		entry.response = response
+		entry.header = header
+	}
+}
+
+// unreplayedHeaders describe a single exchange rather than the response, so
+// a replay gets its own instead of the stored ones. Content-Type and
+// Content-Length are set from the stored response itself.
+var unreplayedHeaders = map[string]bool{}
+
+func init() {
+	for _, name := range []string{
+		"Connection", "Keep-Alive", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+		"Content-Length", "Content-Type", "Date",
+		requestIDHeader, "X-Cold-Start", "X-Jitter-Ms", "X-Queued-Ms",
+		"X-Lambda-Start-Time", "X-Lambda-End-Time", "X-Lambda-Duration", "X-Lambda-Duration-Ms",
+	} {
+		unreplayedHeaders[http.CanonicalHeaderKey(name)] = true
+	}
+}
+
+// replayableHeader copies the headers of h worth restoring on a replay.
+func replayableHeader(h http.Header) http.Header {
+	kept := make(http.Header, len(h))
+	for name, values := range h {
+		if !unreplayedHeaders[name] {
+			kept[name] = append([]string(nil), values...)
+		}
+	}
+	return kept
+}
+
+// release drops a reservation whose request produced nothing worth
+// replaying, so a retry runs again.
+func (s *idempotencyStore) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok && element.Value.(*idempotencyEntry).response == nil {
+		s.remove(element)
+	}
+}
+
+func (s *idempotencyStore) remove(element *list.Element) {
+	delete(s.entries, element.Value.(*idempotencyEntry).key)
+	s.order.Remove(element)
+}
+
+// idempotencyMiddleware replays the stored response, headers included and
+// marked Idempotent-Replayed, when a POST repeats an Idempotency-Key already seen on
+// its route; streamedBodyRoutes are passed through untouched. Reusing a key
+// with a different body is a 422 and repeating one whose first request is
+// still running is a 409. Server errors and canceled requests aren't stored,
+// so they can be retried. It is enabled with ENABLE_IDEMPOTENCY=true.
+func idempotencyMiddleware(store *idempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Streamed routes have no body limit, so buffering them to
+		// fingerprint and replay would hold unbounded data in memory.
+		idempotencyKey := c.GetHeader(idempotencyKeyHeader)
+		if idempotencyKey == "" || c.Request.Method != http.MethodPost || streamedBodyRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		body, ok := bufferBody(c)
+		if !ok {
+			return
+		}
+		route := c.FullPath()
+		key := route + "\x00" + idempotencyKey
+		fingerprint := requestETag(route, c.GetHeader("Accept"), body)
+
+		entry, reserved := store.begin(key, fingerprint, time.Now())
+		if !reserved {
+			switch {
+			case entry.fingerprint != fingerprint:
+				respondError(c, http.StatusUnprocessableEntity, newAPIError("idempotency_key_reused", "Idempotency-Key was used with a different request"))
+			case entry.response == nil:
+				respondError(c, http.StatusConflict, newAPIError("idempotency_in_progress", "a request with this Idempotency-Key is still in progress"))
+			default:
+				for name, values := range entry.header {
+					c.Writer.Header()[name] = append([]string(nil), values...)
+				}
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(entry.response.status, entry.response.contentType, entry.response.body)
+				c.Abort()
+			}
+			return
+		}
+
+		// Released on every path that doesn't store a response, panics
+		// included, so the key never stays stuck in flight.
+		stored := false
+		defer func() {
+			if !stored {
+				store.release(key)
+			}
+		}()
+
+		w := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		if status := w.Status(); status < http.StatusInternalServerError && status != statusClientClosedRequest {
+			store.finish(key, &sharedResponse{status: status, contentType: w.Header().Get("Content-Type"), body: w.body.Bytes()}, replayableHeader(w.Header()))
+			stored = true
+		}
+	}
+}
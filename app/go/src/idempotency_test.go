@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func postWithKey(r http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotencyKeyHeader, key)
+	return serve(r, req)
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	t.Setenv("ENABLE_IDEMPOTENCY", "true")
+	r := newRouter()
+
+	first := postWithKey(r, "/random", "k1", `{"count":3,"seed":7}`)
+	second := postWithKey(r, "/random", "k1", `{"count":3,"seed":7}`)
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("statuses = %d, %d", first.Code, second.Code)
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("first response marked as replayed")
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("second response not marked as replayed")
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("replayed body %s differs from %s", second.Body, first.Body)
+	}
+
+	if w := postWithKey(r, "/random", "k1", `{"count":4}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key status = %d, want 422", w.Code)
+	}
+	if w := postWithKey(r, "/math", "k1", `{"operation":"sum","numbers":[1]}`); w.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("key replayed across routes")
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	t.Setenv("ENABLE_IDEMPOTENCY", "true")
+	r := newRouter()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postWithKey(r, "/sleep", "slow", `{"ms":200}`) }()
+	time.Sleep(50 * time.Millisecond)
+	if w := postWithKey(r, "/sleep", "slow", `{"ms":200}`); w.Code != http.StatusConflict {
+		t.Errorf("concurrent status = %d, want 409", w.Code)
+	}
+	if w := <-done; w.Code != http.StatusOK {
+		t.Errorf("first status = %d", w.Code)
+	}
+}
+
+func TestIdempotencySkipsStreamedRoutes(t *testing.T) {
+	t.Setenv("ENABLE_IDEMPOTENCY", "true")
+	r := newRouter()
+
+	for i := 0; i < 2; i++ {
+		w := postWithKey(r, "/compress/stream", "stream", "streamed body")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d", w.Code)
+		}
+		if w.Header().Get("Idempotent-Replayed") != "" {
+			t.Error("streamed route was replayed")
+		}
+	}
+}
+
+func TestIdempotencyStoreEviction(t *testing.T) {
+	store := newIdempotencyStore(time.Minute, 2)
+	now := time.Now()
+	for _, key := range []string{"a", "b", "c"} {
+		if _, reserved := store.begin(key, "fp", now); !reserved {
+			t.Fatalf("%s not reserved", key)
+		}
+		store.finish(key, &sharedResponse{status: http.StatusOK}, nil)
+	}
+	if _, reserved := store.begin("a", "fp", now); !reserved {
+		t.Error("oldest key survived eviction")
+	}
+	if _, reserved := store.begin("c", "fp", now.Add(2*time.Minute)); !reserved {
+		t.Error("expired key still stored")
+	}
+}
+
+func TestIdempotencyReplaysHeaders(t *testing.T) {
+	t.Setenv("ENABLE_IDEMPOTENCY", "true")
+	r := newRouter()
+	body := `{"operation":"sum","numbers":[0.1,0.2],"summation":"kahan"}`
+
+	first := postWithKey(r, "/math", "h1", body)
+	second := postWithKey(r, "/math", "h1", body)
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("second response not replayed")
+	}
+	if got := second.Header().Get("X-Summation"); got != "kahan" {
+		t.Errorf("replayed X-Summation = %q, want kahan", got)
+	}
+	if got, want := second.Header().Get("Content-Type"), first.Header().Get("Content-Type"); got != want {
+		t.Errorf("replayed Content-Type = %q, want %q", got, want)
+	}
+	if a, b := first.Header().Get(requestIDHeader), second.Header().Get(requestIDHeader); a == b {
+		t.Errorf("replay reused the original request ID %q", a)
+	}
+	if got := second.Header().Values("X-Lambda-Duration-Ms"); len(got) != 1 {
+		t.Errorf("replayed X-Lambda-Duration-Ms = %v, want the replay's own single value", got)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/image/draw"
+)
+
+const (
+	defaultImageSize   = 100
+	maxImageSize       = 2000
+	defaultJPEGQuality = 75
+)
+
+type imageRequest struct {
+	Text    string `json:"text"`
+	Image   string `json:"image"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Format  string `json:"format"`
+	Quality *int   `json:"quality"`
+}
+
+type imageResponse struct {
+	Image string `json:"image"`
+}
+
+func imageHandler(c *gin.Context) {
+	setLazyInitHeader(c, loader.loadImage())
+
+	var payload imageRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	if payload.Width == 0 {
+		payload.Width = defaultImageSize
+	}
+	if payload.Height == 0 {
+		payload.Height = defaultImageSize
+	}
+	if payload.Width < 0 || payload.Height < 0 || payload.Width > maxImageSize || payload.Height > maxImageSize {
+		respondError(c, http.StatusBadRequest, errors.New("width and height must be between 1 and 2000"))
+		return
+	}
+
+	if payload.Format == "" {
+		payload.Format = "png"
+	}
+	quality := defaultJPEGQuality
+	if payload.Quality != nil {
+		quality = *payload.Quality
+	}
+	if payload.Format != "png" && payload.Format != "jpeg" {
+		respondError(c, http.StatusBadRequest, newAPIError("unsupported_format", "unsupported format: "+payload.Format))
+		return
+	}
+	if payload.Format == "jpeg" && (quality < 1 || quality > 100) {
+		respondError(c, http.StatusBadRequest, errors.New("quality must be between 1 and 100"))
+		return
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, payload.Width, payload.Height))
+	if payload.Image != "" {
+		// Resize mode: scale the supplied image to the requested size.
+		src, err := decodeImage(payload.Image)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		draw.CatmullRom.Scale(img, img.Bounds(), src, src.Bounds(), draw.Src, nil)
+	} else {
+		draw.Draw(img, img.Bounds(), &image.Uniform{C: textColor(payload.Text)}, image.Point{}, draw.Src)
+	}
+
+	writeImage(c, img, payload.Format, quality)
+}
+
+// decodeImage decodes a base64 PNG or JPEG, checking its dimensions before
+// decoding pixels so oversized input is rejected cheaply.
+func decodeImage(encoded string) (image.Image, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("image is not valid base64")
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("unsupported or corrupt image: " + err.Error())
+	}
+	if cfg.Width > maxImageSize || cfg.Height > maxImageSize {
+		return nil, errors.New("source image exceeds 2000x2000")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("unsupported or corrupt image: " + err.Error())
+	}
+	return img, nil
+}
+
+// writeImage encodes img as png or jpeg. Clients asking for image/* get the
+// raw bytes under the matching Content-Type; everyone else gets the
+// base64-encoded image in JSON, as /image has always returned.
+func writeImage(c *gin.Context, img image.Image, format string, quality int) {
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	} else {
+		err = pngEncoder.Encode(&buf, img)
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	contentType := "image/" + format
+	c.Header("X-Image-Format", format)
+	if strings.HasPrefix(c.GetHeader("Accept"), "image/") {
+		c.Data(http.StatusOK, contentType, buf.Bytes())
+		return
+	}
+	encodedImage := base64.StdEncoding.EncodeToString(buf.Bytes())
+	c.JSON(http.StatusOK, imageResponse{Image: encodedImage})
+}
+
+// textColor derives a stable fill color from text so the same payload always
+// renders the same image.
+func textColor(text string) color.RGBA {
+	h := fnv.New32a()
+	h.Write([]byte(text))
+	sum := h.Sum32()
+	return color.RGBA{R: uint8(sum >> 16), G: uint8(sum >> 8), B: uint8(sum), A: 0xff}
+}
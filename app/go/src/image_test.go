@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+// decodeImageResponse decodes the base64 image of a JSON /image response.
+func decodeImageResponse(t *testing.T, body map[string]interface{}) (image.Image, string) {
+	t.Helper()
+	encoded, _ := body["image"].(string)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decoding image: %v", err)
+	}
+	return img, format
+}
+
+func TestImageFormats(t *testing.T) {
+	r := newRouter()
+	for _, format := range []string{"png", "jpeg"} {
+		t.Run(format, func(t *testing.T) {
+			w := postJSON(r, "/image", `{"text":"hi","width":16,"height":8,"format":"`+format+`"}`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("X-Image-Format"); got != format {
+				t.Errorf("X-Image-Format = %q", got)
+			}
+			img, got := decodeImageResponse(t, decodeBody(t, w))
+			if got != format {
+				t.Errorf("decoded format = %q, want %q", got, format)
+			}
+			if b := img.Bounds(); b.Dx() != 16 || b.Dy() != 8 {
+				t.Errorf("bounds = %v", b)
+			}
+		})
+	}
+}
+
+func TestImageRejectsBadInput(t *testing.T) {
+	r := newRouter()
+	for _, body := range []string{
+		`{"format":"gif"}`,
+		`{"format":"jpeg","quality":0}`,
+		`{"format":"jpeg","quality":101}`,
+		`{"width":2001}`,
+	} {
+		if w := postJSON(r, "/image", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestImageResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	r := newRouter()
+	w := postJSON(r, "/image", `{"image":"`+encoded+`","width":10,"height":25}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	img, _ := decodeImageResponse(t, decodeBody(t, w))
+	if b := img.Bounds(); b.Dx() != 10 || b.Dy() != 25 {
+		t.Errorf("resized bounds = %v, want 10x25", b)
+	}
+
+	for _, data := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("not an image"))} {
+		if w := postJSON(r, "/image", `{"image":"`+data+`"}`); w.Code != http.StatusBadRequest {
+			t.Errorf("image %q: status = %d, want 400", data, w.Code)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jitterModes are the accepted JITTER_MODE values.
+var jitterModes = map[string]bool{
+	"uniform": true,
+	"normal":  true,
+}
+
+// jitterSource draws delays from a seeded source. "uniform" spreads them
+// evenly over mean±stddev; "normal" samples a normal distribution. Negative
+// draws are clamped to zero.
+type jitterSource struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	mode   string
+	mean   float64
+	stddev float64
+}
+
+func newJitterSource(mode string, mean, stddev time.Duration, seed int64) *jitterSource {
+	return &jitterSource{
+		rng:    rand.New(rand.NewSource(seed)),
+		mode:   mode,
+		mean:   float64(mean),
+		stddev: float64(stddev),
+	}
+}
+
+func (s *jitterSource) next() time.Duration {
+	// rand.Rand is not safe for concurrent use.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var d float64
+	if s.mode == "normal" {
+		d = s.mean + s.rng.NormFloat64()*s.stddev
+	} else {
+		d = s.mean + (2*s.rng.Float64()-1)*s.stddev
+	}
+	return time.Duration(math.Max(d, 0))
+}
+
+// jitterMiddleware delays each request by a draw from source before the
+// handler runs, reporting the delay in X-Jitter-Ms. It is enabled with
+// JITTER_MODE=uniform|normal.
+func jitterMiddleware(source *jitterSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		delay := source.next()
+		c.Header("X-Jitter-Ms", strconv.FormatFloat(float64(delay.Nanoseconds())/1e6, 'f', 3, 64))
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-c.Request.Context().Done():
+			respondIfCanceled(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestJitterHeader(t *testing.T) {
+	t.Setenv("JITTER_MODE", "uniform")
+	t.Setenv("JITTER_MEAN_MS", "5")
+	t.Setenv("JITTER_STDDEV_MS", "3")
+	t.Setenv("JITTER_SEED", "7")
+	r := newRouter()
+
+	var total float64
+	const n = 50
+	distinct := map[string]bool{}
+	for i := 0; i < n; i++ {
+		w := postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`)
+		header := w.Header().Get("X-Jitter-Ms")
+		ms, err := strconv.ParseFloat(header, 64)
+		if err != nil {
+			t.Fatalf("X-Jitter-Ms = %q", header)
+		}
+		if ms < 2 || ms > 8 {
+			t.Errorf("X-Jitter-Ms = %v, want within mean 5 ± 3", ms)
+		}
+		total += ms
+		distinct[header] = true
+	}
+	if mean := total / n; mean < 4 || mean > 6 {
+		t.Errorf("mean jitter = %.3fms over %d requests, want about 5", mean, n)
+	}
+	if len(distinct) < n/2 {
+		t.Errorf("only %d distinct delays in %d requests", len(distinct), n)
+	}
+}
+
+func TestJitterDisabled(t *testing.T) {
+	w := postJSON(newRouter(), "/math", `{"operation":"sum","numbers":[1]}`)
+	if got := w.Header().Get("X-Jitter-Ms"); got != "" {
+		t.Errorf("X-Jitter-Ms = %q without JITTER_MODE", got)
+	}
+}
+
+func TestJitterSeedReproducible(t *testing.T) {
+	for _, seed := range []string{"0", "-42", "9223372036854775807"} {
+		t.Run(seed, func(t *testing.T) {
+			t.Setenv("JITTER_MODE", "uniform")
+			t.Setenv("JITTER_MEAN_MS", "2")
+			t.Setenv("JITTER_STDDEV_MS", "1")
+			t.Setenv("JITTER_SEED", seed)
+			if got := jitterSeed(); strconv.FormatInt(got, 10) != seed {
+				t.Fatalf("jitterSeed() = %d, want %s", got, seed)
+			}
+
+			delays := func() []string {
+				r := newRouter()
+				var out []string
+				for i := 0; i < 5; i++ {
+					w := postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`)
+					out = append(out, w.Header().Get("X-Jitter-Ms"))
+				}
+				return out
+			}
+			first, second := delays(), delays()
+			for i := range first {
+				if first[i] != second[i] {
+					t.Fatalf("seed %s gave %v then %v", seed, first, second)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// jsonPairRequest's Value is a pointer so that required rejects a missing
+// value but still accepts "".
+type jsonPairRequest struct {
+	Key   string  `json:"key" binding:"required"`
+	Value *string `json:"value" binding:"required"`
+}
+
+func jsonHandler(c *gin.Context) {
+	var payload jsonPairRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	respondMarshaled(c, map[string]string{payload.Key: *payload.Value})
+}
+
+// jsonEchoHandler re-marshals an arbitrary JSON object, so payload size
+// directly drives the unmarshal/marshal cost being measured. XML and
+// MessagePack are honored too, via Content-Type and Accept, for comparing
+// encoders.
+func jsonEchoHandler(c *gin.Context) {
+	var payload map[string]interface{}
+	switch contentType := c.ContentType(); {
+	case isXMLType(contentType):
+		var doc xmlDocument
+		if !bindXML(c, &doc) {
+			return
+		}
+		payload = doc
+	case contentType == msgpackContentType:
+		if !bindMsgPack(c, &payload) {
+			return
+		}
+	default:
+		if !bindJSON(c, &payload) {
+			return
+		}
+	}
+
+	switch accept := c.GetHeader("Accept"); {
+	case isXMLType(accept):
+		xmlData, err := xml.Marshal(xmlDocument(payload))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", xmlData)
+	case accept == msgpackContentType:
+		msgpackData, err := msgpack.Marshal(payload)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.Data(http.StatusOK, msgpackContentType, msgpackData)
+	default:
+		respondMarshaled(c, payload)
+	}
+}
+
+const msgpackContentType = "application/msgpack"
+
+func isXMLType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml"
+}
+
+type jsonDataResponse struct {
+	JSONData string `json:"json_data"`
+}
+
+// respondMarshaled responds with v marshaled to a JSON string under
+// "json_data", or with 500 if v can't be marshaled.
+func respondMarshaled(c *gin.Context, v interface{}) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, jsonDataResponse{JSONData: string(jsonData)})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFlattenDepth caps how deeply /json/flatten descends into its input.
+var maxFlattenDepth = 64
+
+// flattenJSON writes every leaf of value into flat under its path: object
+// keys are joined with dots and array elements get [i] suffixes, e.g.
+// "a.b[0].c". Empty objects and arrays are kept as leaves so they survive
+// the round trip.
+func flattenJSON(flat map[string]interface{}, path string, value interface{}, depth int) error {
+	if depth > maxFlattenDepth {
+		return newAPIError("too_deep", fmt.Sprintf("input nested deeper than %d levels", maxFlattenDepth))
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && path != "" {
+			flat[path] = v
+		}
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if err := flattenJSON(flat, childPath, child, depth+1); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			flat[path] = v
+		}
+		for i, child := range v {
+			if err := flattenJSON(flat, path+"["+strconv.Itoa(i)+"]", child, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		flat[path] = v
+	}
+	return nil
+}
+
+func jsonFlattenHandler(c *gin.Context) {
+	var payload map[string]interface{}
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	flat := make(map[string]interface{})
+	if err := flattenJSON(flat, "", payload, 0); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, flat)
+}
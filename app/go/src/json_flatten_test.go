@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONFlatten(t *testing.T) {
+	r := newRouter()
+	tests := []struct {
+		name, body, want string
+	}{
+		{"nested objects", `{"a":{"b":{"c":1}},"d":"x"}`, `{"a.b.c":1,"d":"x"}`},
+		{"arrays", `{"list":[1,[2,3]]}`, `{"list[0]":1,"list[1][0]":2,"list[1][1]":3}`},
+		{"mixed", `{"users":[{"name":"a","tags":["x"]},{"name":"b","tags":[]}],"meta":{}}`,
+			`{"users[0].name":"a","users[0].tags[0]":"x","users[1].name":"b","users[1].tags":[],"meta":{}}`},
+		{"null and bool leaves", `{"a":null,"b":{"c":false}}`, `{"a":null,"b.c":false}`},
+	}
+	for _, tt := range tests {
+		w := postJSON(r, "/json/flatten", tt.body)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.name, w.Code, w.Body)
+			continue
+		}
+		var want map[string]interface{}
+		json.Unmarshal([]byte(tt.want), &want)
+		if got := decodeBody(t, w); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: flattened to %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestJSONFlattenTooDeep(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat(`{"a":`, depth) + "1" + strings.Repeat("}", depth)
+	}
+	r := newRouter()
+	if w := postJSON(r, "/json/flatten", nested(maxFlattenDepth)); w.Code != http.StatusOK {
+		t.Errorf("depth %d: status = %d, want 200", maxFlattenDepth, w.Code)
+	}
+	w := postJSON(r, "/json/flatten", nested(maxFlattenDepth+1))
+	if w.Code != http.StatusBadRequest || errorCode(t, w) != "too_deep" {
+		t.Errorf("depth %d: status = %d, body %s, want 400 too_deep", maxFlattenDepth+1, w.Code, w.Body)
+	}
+}
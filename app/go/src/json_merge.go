@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mergeInto copies src's keys into dst, later values winning. With deep set,
+// keys holding objects on both sides are merged recursively instead of
+// replaced. With concatArrays set, keys holding arrays on both sides are
+// appended rather than replaced, at whatever level the merge reaches. dst is
+// modified; nested objects from either side are copied before being changed.
+func mergeInto(dst, src map[string]interface{}, deep, concatArrays bool) {
+	for key, value := range src {
+		switch existing := dst[key].(type) {
+		case map[string]interface{}:
+			if incoming, ok := value.(map[string]interface{}); ok && deep {
+				merged := make(map[string]interface{}, len(existing)+len(incoming))
+				mergeInto(merged, existing, false, false)
+				mergeInto(merged, incoming, deep, concatArrays)
+				dst[key] = merged
+				continue
+			}
+		case []interface{}:
+			if incoming, ok := value.([]interface{}); ok && concatArrays {
+				dst[key] = append(append([]interface{}{}, existing...), incoming...)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+type jsonMergeRequest struct {
+	Objects      []map[string]interface{} `json:"objects" binding:"required,min=1"`
+	Strategy     string                   `json:"strategy"`
+	ConcatArrays bool                     `json:"concat_arrays"`
+}
+
+type jsonMergeResponse struct {
+	Result map[string]interface{} `json:"result"`
+}
+
+func jsonMergeHandler(c *gin.Context) {
+	var payload jsonMergeRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	var deep bool
+	switch payload.Strategy {
+	case "", "shallow":
+	case "deep":
+		deep = true
+	default:
+		respondError(c, http.StatusBadRequest, &APIError{
+			Code:    "invalid_strategy",
+			Message: "unsupported strategy",
+			Details: map[string]interface{}{"supported": []string{"deep", "shallow"}},
+		})
+		return
+	}
+
+	result := make(map[string]interface{})
+	for _, object := range payload.Objects {
+		mergeInto(result, object, deep, payload.ConcatArrays)
+	}
+	c.JSON(http.StatusOK, jsonMergeResponse{Result: result})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestJSONMerge(t *testing.T) {
+	const objects = `[{"a":1,"cfg":{"x":1,"inner":{"p":1}},"tags":["a"]},{"a":2,"cfg":{"y":2,"inner":{"q":2}},"tags":["b"]},{"a":3}]`
+	tests := []struct {
+		name, options, want string
+	}{
+		{"shallow", `"strategy":"shallow"`,
+			`{"a":3,"cfg":{"y":2,"inner":{"q":2}},"tags":["b"]}`},
+		{"default is shallow", `"strategy":""`,
+			`{"a":3,"cfg":{"y":2,"inner":{"q":2}},"tags":["b"]}`},
+		{"deep", `"strategy":"deep"`,
+			`{"a":3,"cfg":{"x":1,"y":2,"inner":{"p":1,"q":2}},"tags":["b"]}`},
+		{"deep with concat_arrays", `"strategy":"deep","concat_arrays":true`,
+			`{"a":3,"cfg":{"x":1,"y":2,"inner":{"p":1,"q":2}},"tags":["a","b"]}`},
+	}
+	r := newRouter()
+	for _, tt := range tests {
+		w := postJSON(r, "/json/merge", `{"objects":`+objects+`,`+tt.options+`}`)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.name, w.Code, w.Body)
+			continue
+		}
+		var want map[string]interface{}
+		json.Unmarshal([]byte(tt.want), &want)
+		if got := decodeBody(t, w)["result"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: result = %v, want %v", tt.name, got, want)
+		}
+	}
+
+	// A later non-object value replaces an object even when merging deeply.
+	w := postJSON(r, "/json/merge", `{"objects":[{"k":{"x":1}},{"k":"flat"}],"strategy":"deep"}`)
+	if got := decodeBody(t, w)["result"]; !reflect.DeepEqual(got, map[string]interface{}{"k": "flat"}) {
+		t.Errorf("object replaced by scalar: result = %v", got)
+	}
+
+	if w := postJSON(r, "/json/merge", `{"objects":[{}],"strategy":"zip"}`); w.Code != http.StatusBadRequest || errorCode(t, w) != "invalid_strategy" {
+		t.Errorf("unknown strategy: status = %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/gin-gonic/gin"
+)
+
+type jsonSelectRequest struct {
+	Data        interface{} `json:"data" binding:"required"`
+	Paths       []string    `json:"paths" binding:"required,min=1"`
+	OmitMissing bool        `json:"omit_missing"`
+}
+
+type jsonSelectResponse struct {
+	Results map[string]interface{} `json:"results"`
+}
+
+// jsonSelectHandler evaluates each JSONPath expression against data and
+// returns the matches keyed by expression. Expressions that match nothing
+// yield null, or are left out when omit_missing is set.
+func jsonSelectHandler(c *gin.Context) {
+	var payload jsonSelectRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	results := make(map[string]interface{}, len(payload.Paths))
+	for _, path := range payload.Paths {
+		eval, err := jsonpath.New(path)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, &APIError{
+				Code:    "invalid_path",
+				Message: err.Error(),
+				Details: map[string]interface{}{"path": path},
+			})
+			return
+		}
+		// Evaluation only fails on a missing key or out-of-range index.
+		value, err := eval(c.Request.Context(), payload.Data)
+		if err != nil {
+			if payload.OmitMissing {
+				continue
+			}
+			value = nil
+		}
+		results[path] = value
+	}
+	c.JSON(http.StatusOK, jsonSelectResponse{Results: results})
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const selectData = `{"user":{"name":"ada","address":{"city":"London"}},"items":[{"id":1,"price":9.5},{"id":2,"price":3}]}`
+
+func TestJSONSelect(t *testing.T) {
+	r := newRouter()
+	w := postJSON(r, "/json/select", `{"data":`+selectData+`,"paths":["$.user.address.city","$.items[1].id","$.items[*].price","$.missing","$.items[5]"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var got jsonSelectResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"$.user.address.city": "London",
+		"$.items[1].id":       2.0,
+		"$.items[*].price":    []interface{}{9.5, 3.0},
+		"$.missing":           nil,
+		"$.items[5]":          nil,
+	}
+	if !reflect.DeepEqual(got.Results, want) {
+		t.Errorf("results = %v, want %v", got.Results, want)
+	}
+
+	w = postJSON(r, "/json/select", `{"data":`+selectData+`,"paths":["$.user.name","$.missing"],"omit_missing":true}`)
+	got = jsonSelectResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"$.user.name": "ada"}; !reflect.DeepEqual(got.Results, want) {
+		t.Errorf("omit_missing results = %v, want %v", got.Results, want)
+	}
+}
+
+func TestJSONSelectInvalidPath(t *testing.T) {
+	w := postJSON(newRouter(), "/json/select", `{"data":`+selectData+`,"paths":["$.user.name","$.items[?("]}`)
+	if w.Code != http.StatusBadRequest || errorCode(t, w) != "invalid_path" {
+		t.Fatalf("status = %d, body %s, want 400 invalid_path", w.Code, w.Body)
+	}
+	details, _ := decodeBody(t, w)["error"].(map[string]interface{})["details"].(map[string]interface{})
+	if details["path"] != "$.items[?(" {
+		t.Errorf("details = %v, want the offending path", details)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestRespondMarshaledError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	respondMarshaled(c, map[string]interface{}{"ch": make(chan int)})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+	if code := errorCode(t, w); code == "" {
+		t.Errorf("body %s is not an error envelope", w.Body)
+	}
+}
+
+// postEcho sends body to /json/echo with the given Content-Type and Accept.
+func postEcho(h http.Handler, contentType, accept string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/json/echo", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	return serve(h, req)
+}
+
+// echoedJSON decodes the json_data string of a JSON /json/echo response.
+func echoedJSON(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	data, _ := decodeBody(t, w)["json_data"].(string)
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("json_data is not a JSON object: %v: %q", err, data)
+	}
+	return v
+}
+
+func TestJSONEchoXML(t *testing.T) {
+	r := newRouter()
+	const xmlBody = `<doc><name>bench</name><tag>a</tag><tag>b</tag><meta><run>7</run></meta></doc>`
+	want := map[string]interface{}{
+		"name": "bench",
+		"tag":  []interface{}{"a", "b"},
+		"meta": map[string]interface{}{"run": "7"},
+	}
+
+	t.Run("xml in, xml out", func(t *testing.T) {
+		w := postEcho(r, "application/xml", "application/xml", []byte(xmlBody))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/xml; charset=utf-8" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		var doc xmlDocument
+		if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(map[string]interface{}(doc), want) {
+			t.Errorf("document = %v, want %v", doc, want)
+		}
+	})
+
+	t.Run("xml in, json out", func(t *testing.T) {
+		w := postEcho(r, "text/xml", "", []byte(xmlBody))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		if got := echoedJSON(t, w); !reflect.DeepEqual(got, want) {
+			t.Errorf("json_data = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("json in, xml out", func(t *testing.T) {
+		w := postEcho(r, "application/json", "application/xml", []byte(`{"name":"bench","count":3,"tag":["a","b"]}`))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		const wantXML = `<document><count>3</count><name>bench</name><tag>a</tag><tag>b</tag></document>`
+		if got := w.Body.String(); got != wantXML {
+			t.Errorf("body = %s, want %s", got, wantXML)
+		}
+	})
+
+	t.Run("json in, json out", func(t *testing.T) {
+		w := postEcho(r, "application/json", "", []byte(`{"name":"bench"}`))
+		if got := echoedJSON(t, w); !reflect.DeepEqual(got, map[string]interface{}{"name": "bench"}) {
+			t.Errorf("json_data = %v", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if w := postEcho(r, "application/xml", "", []byte(`<doc><open></doc>`)); w.Code != http.StatusBadRequest {
+			t.Errorf("malformed xml: status = %d, want 400", w.Code)
+		}
+		if w := postEcho(r, "application/json", "application/xml", []byte(`{"not a name":1}`)); w.Code != http.StatusBadRequest {
+			t.Errorf("key that is no element name: status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestJSONEchoMsgPack(t *testing.T) {
+	payload := map[string]interface{}{
+		"id":      12345,
+		"name":    "benchmark",
+		"ratio":   0.75,
+		"enabled": true,
+		"tags":    []interface{}{"alpha", "beta", "gamma"},
+		"nested":  map[string]interface{}{"count": 3, "values": []interface{}{1.5, 2.5, 3.5}},
+	}
+	packed, err := msgpack.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter()
+
+	w := postEcho(r, msgpackContentType, msgpackContentType, packed)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Type"); got != msgpackContentType {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var echoed map[string]interface{}
+	if err := msgpack.Unmarshal(w.Body.Bytes(), &echoed); err != nil {
+		t.Fatal(err)
+	}
+	// msgpack decodes small integers to narrower types, so compare the
+	// payloads through JSON.
+	wantJSON, _ := json.Marshal(payload)
+	gotJSON, _ := json.Marshal(echoed)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("round trip = %s, want %s", gotJSON, wantJSON)
+	}
+	if w.Body.Len() >= len(wantJSON) {
+		t.Errorf("msgpack is %d bytes, JSON %d; want msgpack smaller", w.Body.Len(), len(wantJSON))
+	}
+
+	var want map[string]interface{}
+	json.Unmarshal(wantJSON, &want)
+	w = postEcho(r, msgpackContentType, "", packed)
+	if got := echoedJSON(t, w); !reflect.DeepEqual(got, want) {
+		t.Errorf("msgpack in, json out = %v", got)
+	}
+	if w := postEcho(r, msgpackContentType, "", []byte{0xc1}); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed msgpack: status = %d, want 400", w.Code)
+	}
+}
+
+func TestJSONEmptyValue(t *testing.T) {
+	r := newRouter()
+	w := postJSON(r, "/json", `{"key":"a","value":""}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("empty value: status = %d, body %s", w.Code, w.Body)
+	}
+	if got := decodeBody(t, w)["json_data"]; got != `{"a":""}` {
+		t.Errorf("json_data = %v", got)
+	}
+	if w := postJSON(r, "/json", `{"key":"a"}`); w.Code != http.StatusBadRequest || errorCode(t, w) != "validation_failed" {
+		t.Errorf("missing value: status = %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaError is one failed constraint, located by JSON pointer into data.
+type schemaError struct {
+	Path    string                  `json:"path"`
+	Message *jsonschema.OutputError `json:"message"`
+}
+
+// refusingLoader stops client schemas from pulling in files or URLs through
+// $ref; only the submitted document and the bundled metaschemas resolve.
+type refusingLoader struct{}
+
+func (refusingLoader) Load(url string) (any, error) {
+	return nil, errors.New("external references are not allowed: " + url)
+}
+
+func compileSchema(raw json.RawMessage) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(refusingLoader{})
+	if err := compiler.AddResource("mem:///schema.json", doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile("mem:///schema.json")
+}
+
+type jsonValidateRequest struct {
+	Schema json.RawMessage `json:"schema" binding:"required"`
+	Data   json.RawMessage `json:"data" binding:"required"`
+}
+
+// jsonValidateResponse carries Errors only when Valid is false.
+type jsonValidateResponse struct {
+	Errors []schemaError `json:"errors,omitempty"`
+	Valid  bool          `json:"valid"`
+}
+
+func jsonValidateHandler(c *gin.Context) {
+	var payload jsonValidateRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	schema, err := compileSchema(payload.Schema)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("invalid schema: %w", err))
+		return
+	}
+	data, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload.Data))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err = schema.Validate(data)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		c.JSON(http.StatusOK, jsonValidateResponse{Valid: true})
+		return
+	}
+
+	var errs []schemaError
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error != nil {
+			errs = append(errs, schemaError{Path: unit.InstanceLocation, Message: unit.Error})
+		}
+	}
+	c.JSON(http.StatusOK, jsonValidateResponse{Errors: errs, Valid: false})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+const personSchema = `{"type":"object","required":["name"],"properties":{"name":{"type":"string"},"age":{"type":"integer","minimum":0}}}`
+
+func TestJSONValidate(t *testing.T) {
+	r := newRouter()
+
+	w := postJSON(r, "/json/validate", `{"schema":`+personSchema+`,"data":{"name":"Ada","age":36}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if body := decodeBody(t, w); body["valid"] != true || body["errors"] != nil {
+		t.Errorf("passing document = %v", body)
+	}
+
+	w = postJSON(r, "/json/validate", `{"schema":`+personSchema+`,"data":{"name":"Ada","age":-1}}`)
+	body := decodeBody(t, w)
+	errs, _ := body["errors"].([]interface{})
+	if body["valid"] != false || len(errs) == 0 {
+		t.Fatalf("failing document = %v", body)
+	}
+	found := false
+	for _, e := range errs {
+		found = found || e.(map[string]interface{})["path"] == "/age"
+	}
+	if !found {
+		t.Errorf("errors = %v, want one at /age", errs)
+	}
+
+	w = postJSON(r, "/json/validate", `{"schema":{"type":"nonsense"},"data":{}}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("malformed schema: status = %d, body %s", w.Code, w.Body)
+	}
+}
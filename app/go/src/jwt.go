@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type jwtSignRequest struct {
+	Claims map[string]interface{} `json:"claims"`
+	Secret string                 `json:"secret" binding:"required"`
+}
+
+type jwtSignResponse struct {
+	Token string `json:"token"`
+}
+
+func jwtSignHandler(c *gin.Context) {
+	var payload jwtSignRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	// Without this, omitted claims would sign the literal payload null.
+	claims := jwt.MapClaims(payload.Claims)
+	if claims == nil {
+		claims = jwt.MapClaims{}
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(payload.Secret))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, jwtSignResponse{Token: signed})
+}
+
+// jwtFailure names why a token was rejected, keeping expiry and signature
+// failures apart so clients can tell a stale token from a forged one.
+func jwtFailure(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "token expired"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return "invalid signature"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "token not valid yet"
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "malformed token"
+	default:
+		return err.Error()
+	}
+}
+
+type jwtVerifyRequest struct {
+	Token  string `json:"token" binding:"required"`
+	Secret string `json:"secret" binding:"required"`
+}
+
+// jwtVerifyResponse carries Claims for a valid token and Error, naming the
+// failure, for an invalid one.
+type jwtVerifyResponse struct {
+	Claims jwt.MapClaims `json:"claims,omitempty"`
+	Error  string        `json:"error,omitempty"`
+	Valid  bool          `json:"valid"`
+}
+
+func jwtVerifyHandler(c *gin.Context) {
+	var payload jwtVerifyRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(payload.Token, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(payload.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		c.JSON(http.StatusOK, jwtVerifyResponse{Error: jwtFailure(err), Valid: false})
+		return
+	}
+	c.JSON(http.StatusOK, jwtVerifyResponse{Claims: claims, Valid: true})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signJWT(t *testing.T, body string) string {
+	t.Helper()
+	w := postJSON(newRouter(), "/jwt/sign", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("sign status = %d, body %s", w.Code, w.Body)
+	}
+	token, _ := decodeBody(t, w)["token"].(string)
+	return token
+}
+
+func verifyJWT(t *testing.T, token, secret string) map[string]interface{} {
+	t.Helper()
+	w := postJSON(newRouter(), "/jwt/verify", fmt.Sprintf(`{"token":%q,"secret":%q}`, token, secret))
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify status = %d, body %s", w.Code, w.Body)
+	}
+	return decodeBody(t, w)
+}
+
+func TestJWTVerify(t *testing.T) {
+	valid := signJWT(t, `{"claims":{"sub":"alice"},"secret":"s3cret"}`)
+	expired := signJWT(t, fmt.Sprintf(`{"claims":{"exp":%d},"secret":"s3cret"}`, time.Now().Add(-time.Hour).Unix()))
+	parts := strings.Split(valid, ".")
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory"}`)) + "." + parts[2]
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+		valid  bool
+		error  string
+	}{
+		{"valid", valid, "s3cret", true, ""},
+		{"expired", expired, "s3cret", false, "token expired"},
+		{"tampered", tampered, "s3cret", false, "invalid signature"},
+		{"wrong secret", valid, "other", false, "invalid signature"},
+		{"malformed", "not-a-jwt", "s3cret", false, "malformed token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := verifyJWT(t, tt.token, tt.secret)
+			if body["valid"] != tt.valid {
+				t.Errorf("valid = %v, want %v", body["valid"], tt.valid)
+			}
+			if got, _ := body["error"].(string); got != tt.error {
+				t.Errorf("error = %q, want %q", got, tt.error)
+			}
+		})
+	}
+
+	claims, _ := verifyJWT(t, valid, "s3cret")["claims"].(map[string]interface{})
+	if claims["sub"] != "alice" {
+		t.Errorf("claims = %v", claims)
+	}
+}
+
+func TestJWTSignWithoutClaims(t *testing.T) {
+	token := signJWT(t, `{"secret":"s3cret"}`)
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token = %q", token)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != "{}" {
+		t.Errorf("payload = %s, want {}", payload)
+	}
+	if body := verifyJWT(t, token, "s3cret"); body["valid"] != true {
+		t.Errorf("verify = %v", body)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+)
+
+// startLambda serves the handler through the Lambda runtime, translating API
+// Gateway proxy events into HTTP requests.
+func startLambda(h http.Handler) {
+	adapter := httpadapter.New(h)
+	lambda.Start(func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return adapter.ProxyWithContext(ctx, req)
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lazyLoader defers per-endpoint setup to the first request that needs it,
+// so cold starts only pay for what they use.
+type lazyLoader struct {
+	mathLoaded  sync.Once
+	imageLoaded sync.Once
+}
+
+var loader = &lazyLoader{}
+
+var (
+	// operationNames caches supportedOperations for /math error responses.
+	operationNames []string
+	// pngEncoder reuses encoder buffers across /image requests.
+	pngEncoder *png.Encoder
+)
+
+// loadMath builds the /math dispatch table. It reports whether this call
+// performed the initialization.
+func (l *lazyLoader) loadMath() (cold bool) {
+	l.mathLoaded.Do(func() {
+		mathOperations = newMathOperations()
+		operationNames = supportedOperations()
+		cold = true
+	})
+	return cold
+}
+
+// loadImage prepares the PNG encoder and primes its buffer pool by encoding
+// a 1x1 image. It reports whether this call performed the initialization.
+func (l *lazyLoader) loadImage() (cold bool) {
+	l.imageLoaded.Do(func() {
+		pngEncoder = &png.Encoder{BufferPool: &pngBufferPool{}}
+		pngEncoder.Encode(io.Discard, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+		cold = true
+	})
+	return cold
+}
+
+// setLazyInitHeader reports through X-Lazy-Init whether the request paid for
+// a lazy initialization.
+func setLazyInitHeader(c *gin.Context, cold bool) {
+	if cold {
+		c.Header("X-Lazy-Init", "cold")
+	} else {
+		c.Header("X-Lazy-Init", "warm")
+	}
+}
+
+type pngBufferPool struct {
+	pool sync.Pool
+}
+
+func (p *pngBufferPool) Get() *png.EncoderBuffer {
+	buf, _ := p.pool.Get().(*png.EncoderBuffer)
+	return buf
+}
+
+func (p *pngBufferPool) Put(buf *png.EncoderBuffer) {
+	p.pool.Put(buf)
+}
+
+type warmupResponse struct {
+	Warmed bool `json:"warmed"`
+}
+
+// warmupHandler runs every lazy initialization up front so later requests
+// measure warm behavior.
+func warmupHandler(c *gin.Context) {
+	loader.loadMath()
+	loader.loadImage()
+	c.JSON(http.StatusOK, warmupResponse{Warmed: true})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// withFreshLoader swaps in an uninitialized lazyLoader for the rest of the
+// test.
+func withFreshLoader(t *testing.T) {
+	previous := loader
+	loader = &lazyLoader{}
+	t.Cleanup(func() { loader = previous })
+}
+
+func TestLazyInitCold(t *testing.T) {
+	withFreshLoader(t)
+	r := newRouter()
+	mathBody := `{"operation":"sum","numbers":[1]}`
+	if got := postJSON(r, "/math", mathBody).Header().Get("X-Lazy-Init"); got != "cold" {
+		t.Errorf("first /math X-Lazy-Init = %q, want cold", got)
+	}
+	if got := postJSON(r, "/math", mathBody).Header().Get("X-Lazy-Init"); got != "warm" {
+		t.Errorf("second /math X-Lazy-Init = %q, want warm", got)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	withFreshLoader(t)
+	r := newRouter()
+	w := postJSON(r, "/warmup", "")
+	if w.Code != http.StatusOK || decodeBody(t, w)["warmed"] != true {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	for path, body := range map[string]string{
+		"/math":  `{"operation":"sum","numbers":[1]}`,
+		"/image": `{"text":"hi","width":4,"height":4}`,
+	} {
+		if got := postJSON(r, path, body).Header().Get("X-Lazy-Init"); got != "warm" {
+			t.Errorf("%s after /warmup: X-Lazy-Init = %q, want warm", path, got)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inflightLimiter allows at most max requests to be served at once and
+// rejects the excess with 429 rather than queueing them.
+func inflightLimiter(max int) gin.HandlerFunc {
+	slots := make(chan struct{}, max)
+	return func(c *gin.Context) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			c.Next()
+		default:
+			c.Header("Retry-After", "1")
+			respondError(c, http.StatusTooManyRequests, newAPIError("too_many_inflight", "too many in-flight requests"))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestInflightLimiterSparesProbes fills the only in-flight slot with a slow
+// request and checks that work routes are rejected while probes still answer.
+func TestInflightLimiterSparesProbes(t *testing.T) {
+	t.Setenv("MAX_INFLIGHT", "1")
+	r := newRouter()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		postJSON(r, "/sleep", `{"ms":300}`)
+	}()
+	defer func() { <-done }()
+	time.Sleep(50 * time.Millisecond)
+
+	rejected := requestCount(t, r, "/math", http.MethodPost, "429")
+	w := postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`)
+	if w.Code != http.StatusTooManyRequests || errorCode(t, w) != "too_many_inflight" {
+		t.Errorf("/math while saturated: status = %d, body %s", w.Code, w.Body)
+	}
+	if w.Header().Get("X-Lambda-Duration-Ms") == "" {
+		t.Error("rejection has no timing headers")
+	}
+	if got := requestCount(t, r, "/math", http.MethodPost, "429"); got != rejected+1 {
+		t.Errorf("http_requests_total for the 429 = %v, want %v", got, rejected+1)
+	}
+	for _, path := range []string{"/health", "/metrics", "/stats"} {
+		if w := serve(r, httptest.NewRequest(http.MethodGet, path, nil)); w.Code != http.StatusOK {
+			t.Errorf("%s while saturated: status = %d", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loggerKey is the gin context key holding the request-scoped logger.
+const loggerKey = "logger"
+
+// newLogger builds the process logger: format "text" selects slog's
+// key=value handler, anything else emits one JSON object per record. Records
+// below level (debug, info, warn or error; info if unset or unknown) are
+// dropped.
+func newLogger(format, level string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLogLevel(level)}
+	if format == "text" {
+		return slog.New(slog.NewTextHandler(os.Stdout, opts))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, opts))
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// requestLogger returns the logger requestIDMiddleware attached to c, which
+// tags every record with the request ID, or the default logger outside it.
+func requestLogger(c *gin.Context) *slog.Logger {
+	if value, ok := c.Get(loggerKey); ok {
+		return value.(*slog.Logger)
+	}
+	return slog.Default()
+}
+
+// accessLogger emits one info record per request once the chain finishes.
+func accessLogger(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	requestLogger(c).Info("request",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"duration_ms", float64(time.Since(start).Microseconds())/1000,
+	)
+}
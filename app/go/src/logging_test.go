@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newFileLogger returns newLogger(format, level) writing to a temporary file
+// in place of stdout, plus a function reading back what has been logged.
+func newFileLogger(t *testing.T, format, level string) (*slog.Logger, func() []byte) {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	stdout := os.Stdout
+	os.Stdout = f
+	logger := newLogger(format, level)
+	os.Stdout = stdout
+
+	return logger, func() []byte {
+		data, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+}
+
+// useDefaultLogger installs logger as the default for the rest of the test.
+func useDefaultLogger(t *testing.T, logger *slog.Logger) {
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+}
+
+func TestJSONAccessLog(t *testing.T) {
+	logger, output := newFileLogger(t, "json", "info")
+	useDefaultLogger(t, logger)
+
+	r := newRouter()
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(requestIDHeader, "log-test")
+	serve(r, req)
+	postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`)
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(output()))
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("log line is not JSON: %v: %s", err, scanner.Bytes())
+		}
+		if record["msg"] == "request" {
+			records = append(records, record)
+		}
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d access records, want 2", len(records))
+	}
+	for _, field := range []string{"method", "path", "status", "duration_ms", "request_id"} {
+		if _, ok := records[0][field]; !ok {
+			t.Errorf("access record missing %q: %v", field, records[0])
+		}
+	}
+	if records[0]["request_id"] != "log-test" || records[0]["path"] != "/health" || records[0]["status"] != float64(http.StatusOK) {
+		t.Errorf("first record = %v", records[0])
+	}
+	if records[1]["path"] != "/math" || records[1]["request_id"] == "" {
+		t.Errorf("second record = %v", records[1])
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	tests := []struct {
+		level string
+		want  []string
+	}{
+		{"debug", []string{"DEBUG", "INFO", "WARN", "ERROR"}},
+		{"", []string{"INFO", "WARN", "ERROR"}},
+		{"bogus", []string{"INFO", "WARN", "ERROR"}},
+		{"WARN", []string{"WARN", "ERROR"}},
+		{"error", []string{"ERROR"}},
+	}
+	for _, tt := range tests {
+		logger, output := newFileLogger(t, "json", tt.level)
+		logger.Debug("d")
+		logger.Info("i")
+		logger.Warn("w")
+		logger.Error("e")
+
+		var levels []string
+		scanner := bufio.NewScanner(bytes.NewReader(output()))
+		for scanner.Scan() {
+			var record struct{ Level string }
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("log line is not JSON: %v: %s", err, scanner.Bytes())
+			}
+			levels = append(levels, record.Level)
+		}
+		if !reflect.DeepEqual(levels, tt.want) {
+			t.Errorf("LOG_LEVEL=%q: logged %v, want %v", tt.level, levels, tt.want)
+		}
+	}
+}
+
+func TestTextLogFormat(t *testing.T) {
+	logger, output := newFileLogger(t, "text", "info")
+	logger.Info("hello", "key", "value")
+	if got := string(output()); !strings.Contains(got, "level=INFO msg=hello key=value") {
+		t.Errorf("text output = %q", got)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mathOperation folds a non-empty slice of numbers into a single result.
+type mathOperation func(numbers []float64) (float64, error)
+
+var errDivisionByZero = errors.New("division by zero")
+
+var errNotFinite = newAPIError("non_finite_result", "result is not finite")
+
+// mathOperations is the single source of truth for the operations /math
+// accepts; supportedOperations is derived from it. It is built on first use
+// by loader.loadMath.
+var mathOperations map[string]mathOperation
+
+func newMathOperations() map[string]mathOperation {
+	return map[string]mathOperation{
+		"sum":      sum,
+		"product":  product,
+		"subtract": subtract,
+		"divide":   divide,
+		"mean":     func(numbers []float64) (float64, error) { return mean(numbers), nil },
+		"median":   func(numbers []float64) (float64, error) { return median(numbers), nil },
+		"stddev":   func(numbers []float64) (float64, error) { return stddev(numbers), nil },
+		"min":      func(numbers []float64) (float64, error) { return extremum(numbers, math.Min) },
+		"max":      func(numbers []float64) (float64, error) { return extremum(numbers, math.Max) },
+		"power":    power,
+		"modulo":   modulo,
+	}
+}
+
+// supportedOperations returns the names of all /math operations, sorted.
+func supportedOperations() []string {
+	names := make([]string, 0, len(mathOperations)+len(bigOperations))
+	for name := range mathOperations {
+		names = append(names, name)
+	}
+	for name := range bigOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mathJob is a single /math request; /math/batch accepts a list of them.
+// In "int" mode the numbers are decoded into IntNumbers instead of Numbers.
+// Empty numbers are a 400 either way; Strict only changes the error, which it
+// reports up front and names the operation in, before mode or operation are
+// even checked. Precision, if set, rounds float results, and Summation picks
+// the strategy for sum.
+type mathJob struct {
+	Numbers    []float64 `json:"numbers" binding:"required_unless=Mode int"`
+	IntNumbers []int64   `json:"-"`
+	Operation  string    `json:"operation" binding:"required"`
+	Mode       string    `json:"mode"`
+	Strict     bool      `json:"strict"`
+	Precision  *int      `json:"precision"`
+	Summation  string    `json:"summation"`
+}
+
+func (job *mathJob) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Numbers   json.RawMessage `json:"numbers"`
+		Operation string          `json:"operation"`
+		Mode      string          `json:"mode"`
+		Strict    bool            `json:"strict"`
+		Precision *int            `json:"precision"`
+		Summation string          `json:"summation"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	job.Operation = aux.Operation
+	job.Mode = aux.Mode
+	job.Strict = aux.Strict
+	job.Precision = aux.Precision
+	job.Summation = aux.Summation
+	if len(aux.Numbers) == 0 {
+		return nil
+	}
+	if job.Mode == "int" {
+		return json.Unmarshal(aux.Numbers, &job.IntNumbers)
+	}
+	return json.Unmarshal(aux.Numbers, &job.Numbers)
+}
+
+var errUnsupportedOperation = errors.New("unsupported operation")
+
+// run validates the job and applies its operation unless ctx is already done.
+// The result is a float64, or an int64 in "int" mode. ctx is only checked up
+// front: the body limit caps numbers at about half a million, so a pass over
+// them takes under a millisecond and median's sort tens of milliseconds, and
+// a canceled job runs to completion rather than polling inside the loops.
+func (job mathJob) run(ctx context.Context) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if job.Strict && len(job.Numbers) == 0 && len(job.IntNumbers) == 0 {
+		return nil, fmt.Errorf("numbers must not be empty for operation %s", job.Operation)
+	}
+	if job.Precision != nil && (*job.Precision < 0 || *job.Precision > maxPrecision) {
+		return nil, fmt.Errorf("precision must be between 0 and %d", maxPrecision)
+	}
+	switch job.Mode {
+	case "", "float":
+	case "int":
+		return job.runInt()
+	default:
+		return nil, errors.New("unsupported mode: " + job.Mode)
+	}
+	if len(job.Numbers) == 0 {
+		return nil, errors.New("numbers must not be empty")
+	}
+	if operation, ok := bigOperations[job.Operation]; ok {
+		result, err := operation(job.Numbers)
+		if err != nil {
+			return nil, err
+		}
+		return result.String(), nil
+	}
+	operation, ok := mathOperations[job.Operation]
+	if !ok {
+		return nil, errUnsupportedOperation
+	}
+	if job.Summation != "" {
+		if job.Operation != "sum" {
+			return nil, errors.New("summation only applies to sum")
+		}
+		if operation, ok = summations[job.Summation]; !ok {
+			return nil, errors.New("unsupported summation: " + job.Summation)
+		}
+	}
+	result, err := operation(job.Numbers)
+	if err != nil {
+		return nil, err
+	}
+	// encoding/json refuses NaN and Inf, so they must not reach the response.
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return nil, errNotFinite
+	}
+	if job.Precision != nil {
+		result = roundTo(result, *job.Precision)
+	}
+	return result, nil
+}
+
+// maxPrecision is the most decimal places a float64 result can meaningfully
+// be rounded to.
+const maxPrecision = 15
+
+// roundTo rounds x to the given number of decimal places, returning x as-is
+// when scaling it would overflow.
+func roundTo(x float64, places int) float64 {
+	scale := math.Pow10(places)
+	scaled := x * scale
+	if math.IsInf(scaled, 0) {
+		return x
+	}
+	return math.Round(scaled) / scale
+}
+
+// supported lists the operations valid for the job's mode.
+func (job mathJob) supported() []string {
+	if job.Mode == "int" {
+		return intOperationNames
+	}
+	return operationNames
+}
+
+// mathResponse's Result is a number, or a decimal string in decimal mode.
+type mathResponse struct {
+	Result interface{} `json:"result"`
+}
+
+func mathHandler(c *gin.Context) {
+	setLazyInitHeader(c, loader.loadMath())
+
+	var payload mathJob
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	result, err := payload.run(c.Request.Context())
+	if respondIfCanceled(c) {
+		return
+	}
+	if errors.Is(err, errUnsupportedOperation) {
+		respondError(c, http.StatusBadRequest, &APIError{
+			Code:    "invalid_operation",
+			Message: err.Error(),
+			Details: map[string]interface{}{"supported": payload.supported()},
+		})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if payload.Operation == "sum" && payload.Mode != "int" {
+		c.Header("X-Summation", payload.summation())
+	}
+	c.JSON(http.StatusOK, mathResponse{Result: result})
+}
+
+func sum(numbers []float64) (float64, error) {
+	var result float64
+	for _, num := range numbers {
+		result += num
+	}
+	return result, nil
+}
+
+func product(numbers []float64) (float64, error) {
+	result := 1.0
+	for _, num := range numbers {
+		result *= num
+	}
+	return result, nil
+}
+
+func subtract(numbers []float64) (float64, error) {
+	result := numbers[0]
+	for _, num := range numbers[1:] {
+		result -= num
+	}
+	return result, nil
+}
+
+func divide(numbers []float64) (float64, error) {
+	result := numbers[0]
+	for _, num := range numbers[1:] {
+		if num == 0 {
+			return 0, errDivisionByZero
+		}
+		result /= num
+	}
+	return result, nil
+}
+
+func mean(numbers []float64) float64 {
+	var sum float64
+	for _, num := range numbers {
+		sum += num
+	}
+	return sum / float64(len(numbers))
+}
+
+// median sorts a copy so the caller's slice keeps its original order.
+func median(numbers []float64) float64 {
+	sorted := append([]float64(nil), numbers...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// stddev returns the population standard deviation.
+func stddev(numbers []float64) float64 {
+	m := mean(numbers)
+	var sumSquares float64
+	for _, num := range numbers {
+		sumSquares += (num - m) * (num - m)
+	}
+	return math.Sqrt(sumSquares / float64(len(numbers)))
+}
+
+// extremum reduces numbers with pick (math.Min or math.Max), rejecting NaN
+// since the ordering is ill-defined.
+func extremum(numbers []float64, pick func(a, b float64) float64) (float64, error) {
+	result := numbers[0]
+	for _, num := range numbers {
+		if math.IsNaN(num) {
+			return 0, errors.New("NaN is not supported for min/max")
+		}
+		result = pick(result, num)
+	}
+	return result, nil
+}
+
+// power returns numbers[0] raised to numbers[1].
+func power(numbers []float64) (float64, error) {
+	if len(numbers) != 2 {
+		return 0, errors.New("power requires exactly two operands: base and exponent")
+	}
+	return math.Pow(numbers[0], numbers[1]), nil
+}
+
+// modulo returns the floating-point remainder of numbers[0] / numbers[1].
+func modulo(numbers []float64) (float64, error) {
+	if len(numbers) != 2 {
+		return 0, errors.New("modulo requires exactly two operands: dividend and divisor")
+	}
+	if numbers[1] == 0 {
+		return 0, errors.New("modulo by zero")
+	}
+	return math.Mod(numbers[0], numbers[1]), nil
+}
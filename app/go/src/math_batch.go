@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBatchJobs = 1000
+
+// mathJobResult holds either a result or an error for one batch job.
+type mathJobResult struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// runBatchJob decodes and runs one job. Jobs are kept raw until here so a
+// malformed entry only fails its own slot.
+func runBatchJob(ctx context.Context, raw json.RawMessage) mathJobResult {
+	var job mathJob
+	if err := json.Unmarshal(raw, &job); err != nil {
+		return mathJobResult{Error: err.Error()}
+	}
+	result, err := job.run(ctx)
+	if err != nil {
+		return mathJobResult{Error: err.Error()}
+	}
+	return mathJobResult{Result: result}
+}
+
+type mathBatchRequest struct {
+	Jobs        []json.RawMessage `json:"jobs"`
+	Parallelism int               `json:"parallelism"`
+}
+
+type mathBatchResponse struct {
+	Results []mathJobResult `json:"results"`
+}
+
+func mathBatchHandler(c *gin.Context) {
+	setLazyInitHeader(c, loader.loadMath())
+
+	var payload mathBatchRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if len(payload.Jobs) > maxBatchJobs {
+		respondError(c, http.StatusBadRequest, errors.New("too many jobs: at most 1000 are allowed"))
+		return
+	}
+
+	if c.GetHeader("Accept") == ndjsonContentType {
+		streamBatchResults(c, payload.Jobs, clampParallelism(payload.Parallelism))
+		return
+	}
+
+	results := runBatch(c.Request.Context(), payload.Jobs, clampParallelism(payload.Parallelism))
+	if respondIfCanceled(c) {
+		return
+	}
+	c.JSON(http.StatusOK, mathBatchResponse{Results: results})
+}
+
+// clampParallelism defaults to sequential execution and caps the worker count
+// at runtime.NumCPU()*4.
+func clampParallelism(n int) int {
+	if n < 1 {
+		return 1
+	}
+	if max := runtime.NumCPU() * 4; n > max {
+		return max
+	}
+	return n
+}
+
+// runBatch runs jobs with at most parallelism in flight. Each job writes to
+// its own index, so results keep the input order. Once ctx is done the
+// remaining jobs fail fast with the context error.
+func runBatch(ctx context.Context, jobs []json.RawMessage, parallelism int) []mathJobResult {
+	results := make([]mathJobResult, len(jobs))
+	if parallelism == 1 {
+		for i, raw := range jobs {
+			results[i] = runBatchJob(ctx, raw)
+		}
+		return results
+	}
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, raw := range jobs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, raw json.RawMessage) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = runBatchJob(ctx, raw)
+		}(i, raw)
+	}
+	wg.Wait()
+	return results
+}
+
+const ndjsonContentType = "application/x-ndjson"
+
+// batchStreamLine is one NDJSON line; Index ties it back to its job since
+// lines are written in completion order.
+type batchStreamLine struct {
+	Index int `json:"index"`
+	mathJobResult
+}
+
+// streamBatchResults writes each job's result as its own line as soon as the
+// job finishes, flushing after every line. If a write fails the batch is
+// canceled, so no job is left blocked on a line nobody will read.
+func streamBatchResults(c *gin.Context, jobs []json.RawMessage, parallelism int) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	c.Header("Content-Type", ndjsonContentType)
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	for line := range streamBatch(ctx, jobs, parallelism) {
+		if err := enc.Encode(line); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// streamBatch runs jobs like runBatch but delivers each result on the
+// returned channel as it completes, closing it once all are done. Once ctx is
+// done it starts no more jobs and drops undelivered results, since the reader
+// may have stopped.
+func streamBatch(ctx context.Context, jobs []json.RawMessage, parallelism int) <-chan batchStreamLine {
+	lines := make(chan batchStreamLine, parallelism)
+	go func() {
+		defer close(lines)
+		sem := make(chan struct{}, parallelism)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		for i, raw := range jobs {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(i int, raw json.RawMessage) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				select {
+				case lines <- batchStreamLine{Index: i, mathJobResult: runBatchJob(ctx, raw)}:
+				case <-ctx.Done():
+				}
+			}(i, raw)
+		}
+	}()
+	return lines
+}
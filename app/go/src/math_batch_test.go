@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// batchBody builds a /math/batch payload of n sum jobs, job i summing [i, 1].
+func batchBody(n, parallelism int) string {
+	jobs := make([]string, n)
+	for i := range jobs {
+		jobs[i] = fmt.Sprintf(`{"operation":"sum","numbers":[%d,1]}`, i)
+	}
+	return fmt.Sprintf(`{"jobs":[%s],"parallelism":%d}`, strings.Join(jobs, ","), parallelism)
+}
+
+func postNDJSON(w http.ResponseWriter, body string) {
+	req := httptest.NewRequest(http.MethodPost, "/math/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", ndjsonContentType)
+	newRouter().ServeHTTP(w, req)
+}
+
+func TestMathBatchNDJSON(t *testing.T) {
+	const jobs = 25
+	w := httptest.NewRecorder()
+	postNDJSON(w, batchBody(jobs, 4))
+
+	if got := w.Header().Get("Content-Type"); got != ndjsonContentType {
+		t.Errorf("Content-Type = %q", got)
+	}
+	seen := make(map[int]bool)
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var line struct {
+			Index  int     `json:"index"`
+			Result float64 `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("bad line %q: %v", scanner.Text(), err)
+		}
+		if line.Result != float64(line.Index+1) {
+			t.Errorf("line %d result = %v", line.Index, line.Result)
+		}
+		seen[line.Index] = true
+	}
+	if len(seen) != jobs {
+		t.Errorf("got %d distinct lines, want %d", len(seen), jobs)
+	}
+}
+
+// brokenWriter fails every body write, as a response to a vanished client
+// would.
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (brokenWriter) Write([]byte) (int, error) {
+	return 0, errors.New("client gone")
+}
+
+func TestMathBatchNDJSONWriteErrorReleasesWorkers(t *testing.T) {
+	before := runtime.NumGoroutine()
+	postNDJSON(brokenWriter{httptest.NewRecorder()}, batchBody(50, 2))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running, had %d before the request", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMathBatchParallelMatchesSequential(t *testing.T) {
+	jobs := make([]string, 100)
+	for i := range jobs {
+		switch i % 4 {
+		case 0:
+			jobs[i] = fmt.Sprintf(`{"operation":"sum","numbers":[%d,0.5]}`, i)
+		case 1:
+			jobs[i] = fmt.Sprintf(`{"operation":"product","numbers":[%d,2]}`, i)
+		case 2:
+			jobs[i] = fmt.Sprintf(`{"operation":"median","numbers":[%d,1,%d]}`, i, i*3)
+		default:
+			jobs[i] = `{"operation":"nope","numbers":[1]}`
+		}
+	}
+	run := func(parallelism int) string {
+		body := fmt.Sprintf(`{"jobs":[%s],"parallelism":%d}`, strings.Join(jobs, ","), parallelism)
+		w := postJSON(newRouter(), "/math/batch", body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("parallelism %d: status = %d, body %s", parallelism, w.Code, w.Body)
+		}
+		return w.Body.String()
+	}
+
+	sequential := run(1)
+	var results struct {
+		Results []mathJobResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(sequential), &results); err != nil {
+		t.Fatal(err)
+	}
+	for i, result := range results.Results {
+		if i%4 == 0 && result.Result != float64(i)+0.5 {
+			t.Errorf("result %d = %v, want %v", i, result.Result, float64(i)+0.5)
+		}
+		if i%4 == 3 && result.Error == "" {
+			t.Errorf("result %d has no error", i)
+		}
+	}
+	for _, parallelism := range []int{4, 16} {
+		if got := run(parallelism); got != sequential {
+			t.Errorf("parallelism %d results differ from sequential:\n%s\n%s", parallelism, got, sequential)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// bigOperation works on integer-valued operands with arbitrary precision;
+// results are returned as decimal strings so they never overflow.
+type bigOperation func(numbers []float64) (*big.Int, error)
+
+const maxFactorialN = 100000
+
+var bigOperations = map[string]bigOperation{
+	"factorial": factorial,
+	"gcd":       gcd,
+	"lcm":       lcm,
+}
+
+var errNonInteger = errors.New("operands must be integers")
+
+// toBigInts converts integer-valued floats to big.Int, rejecting fractions.
+func toBigInts(numbers []float64) ([]*big.Int, error) {
+	ints := make([]*big.Int, len(numbers))
+	for i, num := range numbers {
+		if math.IsInf(num, 0) || num != math.Trunc(num) {
+			return nil, errNonInteger
+		}
+		ints[i], _ = new(big.Float).SetFloat64(num).Int(nil)
+	}
+	return ints, nil
+}
+
+func factorial(numbers []float64) (*big.Int, error) {
+	if len(numbers) != 1 {
+		return nil, errors.New("factorial requires exactly one operand")
+	}
+	n := numbers[0]
+	if n != math.Trunc(n) {
+		return nil, errNonInteger
+	}
+	if n < 0 {
+		return nil, errors.New("factorial is undefined for negative numbers")
+	}
+	if n > maxFactorialN {
+		return nil, fmt.Errorf("factorial operand must be at most %d", maxFactorialN)
+	}
+	if n == 0 {
+		return big.NewInt(1), nil
+	}
+	return new(big.Int).MulRange(1, int64(n)), nil
+}
+
+func gcd(numbers []float64) (*big.Int, error) {
+	if len(numbers) < 2 {
+		return nil, errors.New("gcd requires at least two operands")
+	}
+	ints, err := toBigInts(numbers)
+	if err != nil {
+		return nil, err
+	}
+	result := new(big.Int).Abs(ints[0])
+	for _, n := range ints[1:] {
+		result.GCD(nil, nil, result, new(big.Int).Abs(n))
+	}
+	return result, nil
+}
+
+func lcm(numbers []float64) (*big.Int, error) {
+	if len(numbers) < 2 {
+		return nil, errors.New("lcm requires at least two operands")
+	}
+	ints, err := toBigInts(numbers)
+	if err != nil {
+		return nil, err
+	}
+	result := new(big.Int).Abs(ints[0])
+	for _, n := range ints[1:] {
+		n = new(big.Int).Abs(n)
+		if result.Sign() == 0 || n.Sign() == 0 {
+			result.SetInt64(0)
+			continue
+		}
+		g := new(big.Int).GCD(nil, nil, result, n)
+		result.Mul(result, n.Quo(n, g))
+	}
+	return result, nil
+}
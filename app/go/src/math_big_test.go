@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMathBigOperations(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"operation":"factorial","numbers":[0]}`, "1"},
+		{`{"operation":"factorial","numbers":[1]}`, "1"},
+		{`{"operation":"factorial","numbers":[25]}`, "15511210043330985984000000"},
+		{`{"operation":"gcd","numbers":[17,31]}`, "1"},
+		{`{"operation":"gcd","numbers":[12,18,30]}`, "6"},
+		{`{"operation":"lcm","numbers":[4,6]}`, "12"},
+	}
+	for _, tt := range tests {
+		status, body := postMath(t, tt.body)
+		if status != http.StatusOK || body["result"] != tt.want {
+			t.Errorf("%s: status = %d, result %v, want %s", tt.body, status, body["result"], tt.want)
+		}
+	}
+
+	for _, body := range []string{
+		`{"operation":"factorial","numbers":[-1]}`,
+		`{"operation":"factorial","numbers":[2.5]}`,
+		`{"operation":"gcd","numbers":[4]}`,
+		`{"operation":"lcm","numbers":[4,1.5]}`,
+	} {
+		if status, _ := postMath(t, body); status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, status)
+		}
+	}
+}
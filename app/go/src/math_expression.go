@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exprError is a parse or evaluation failure at a byte offset in the input.
+type exprError struct {
+	Pos int
+	Msg string
+}
+
+func (e *exprError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// exprParser evaluates infix arithmetic by recursive descent:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = [ "-" | "+" ] ( number | "(" expr ")" )
+type exprParser struct {
+	input string
+	pos   int
+	depth int
+}
+
+// maxExprDepth bounds nesting of parentheses and unary signs so a hostile
+// expression cannot exhaust the stack.
+const maxExprDepth = 1000
+
+func evaluateExpression(input string) (float64, error) {
+	p := &exprParser{input: input}
+	result, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, p.errorf("unexpected %q", p.input[p.pos])
+	}
+	return result, nil
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return &exprError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or 0 at end of input.
+func (p *exprParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *exprParser) expr() (float64, error) {
+	left, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+func (p *exprParser) term() (float64, error) {
+	left, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		opPos := p.pos
+		p.pos++
+		right, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			left *= right
+		} else {
+			if right == 0 {
+				return 0, &exprError{Pos: opPos, Msg: errDivisionByZero.Error()}
+			}
+			left /= right
+		}
+	}
+}
+
+func (p *exprParser) factor() (float64, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExprDepth {
+		return 0, p.errorf("expression nested too deeply")
+	}
+
+	switch c := p.peek(); {
+	case c == '-' || c == '+':
+		p.pos++
+		value, err := p.factor()
+		if c == '-' {
+			value = -value
+		}
+		return value, err
+	case c == '(':
+		p.pos++
+		value, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, p.errorf("expected ')'")
+		}
+		p.pos++
+		return value, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c == 0:
+		return 0, p.errorf("unexpected end of expression")
+	default:
+		return 0, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *exprParser) number() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+		p.pos++
+	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return 0, &exprError{Pos: start, Msg: "invalid number " + strconv.Quote(p.input[start:p.pos])}
+	}
+	return value, nil
+}
+
+type mathExpressionRequest struct {
+	Expr string `json:"expr"`
+}
+
+type mathExpressionResponse struct {
+	Result float64 `json:"result"`
+}
+
+func mathExpressionHandler(c *gin.Context) {
+	var payload mathExpressionRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	result, err := evaluateExpression(payload.Expr)
+	var exprErr *exprError
+	if errors.As(err, &exprErr) {
+		respondError(c, http.StatusBadRequest, &APIError{
+			Code:    "invalid_expression",
+			Message: exprErr.Msg,
+			Details: map[string]interface{}{"position": exprErr.Pos},
+		})
+		return
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		respondError(c, http.StatusBadRequest, errNotFinite)
+		return
+	}
+	c.JSON(http.StatusOK, mathExpressionResponse{Result: result})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"2 * 3 + 1", 7},
+		{"10 - 4 - 3", 3},
+		{"64 / 4 / 2", 8},
+		{"2 - 3 * 4 / 6", 0},
+		{"(1 + 2) * 3", 9},
+		{"((2))", 2},
+		{"2 * (3 + (4 - 1)) / 3", 4},
+		{"-3 + 5", 2},
+		{"+3", 3},
+		{"--3", 3},
+		{"-(2 + 3) * 2", -10},
+		{"2 * -3", -6},
+		{"1 - -1", 2},
+		{".5 + 1.25", 1.75},
+		{"\t 7 ", 7},
+	}
+	for _, tt := range tests {
+		got, err := evaluateExpression(tt.expr)
+		if err != nil || got != tt.want {
+			t.Errorf("%q = %v, %v; want %v", tt.expr, got, err, tt.want)
+		}
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{"1 / 0", 2, errDivisionByZero.Error()},
+		{"4 + 8 / (2 - 2)", 6, errDivisionByZero.Error()},
+		{"(1 + 2", 6, "expected ')'"},
+		{"((1)", 4, "expected ')'"},
+		{"1 + 2)", 5, `unexpected ')'`},
+		{"1 2", 2, `unexpected '2'`},
+		{"3 * x", 4, `unexpected 'x'`},
+		{"1 +", 3, "unexpected end of expression"},
+		{"", 0, "unexpected end of expression"},
+		{"1.2.3 + 1", 0, `invalid number "1.2.3"`},
+		{"2 * .", 4, `invalid number "."`},
+	}
+	for _, tt := range tests {
+		_, err := evaluateExpression(tt.expr)
+		var exprErr *exprError
+		if !errors.As(err, &exprErr) {
+			t.Errorf("%q: err = %v, want an exprError", tt.expr, err)
+			continue
+		}
+		if exprErr.Pos != tt.pos || exprErr.Msg != tt.msg {
+			t.Errorf("%q: %q at %d, want %q at %d", tt.expr, exprErr.Msg, exprErr.Pos, tt.msg, tt.pos)
+		}
+	}
+}
+
+func TestMathExpressionDepthLimit(t *testing.T) {
+	r := newRouter()
+	for name, expr := range map[string]string{
+		"parentheses": strings.Repeat("(", 100000) + "1" + strings.Repeat(")", 100000),
+		"unary signs": strings.Repeat("-", 100000) + "1",
+	} {
+		w := postJSON(r, "/math/expression", `{"expr":"`+expr+`"}`)
+		if w.Code != http.StatusBadRequest || errorCode(t, w) != "invalid_expression" {
+			t.Errorf("%s: status = %d, want 400 invalid_expression", name, w.Code)
+			continue
+		}
+		if got := errorMessage(decodeBody(t, w)); got != "expression nested too deeply" {
+			t.Errorf("%s: message = %q", name, got)
+		}
+	}
+
+	nested := strings.Repeat("(", maxExprDepth-1) + "1" + strings.Repeat(")", maxExprDepth-1)
+	if got, err := evaluateExpression(nested); err != nil || got != 1 {
+		t.Errorf("%d levels: %v, %v; want 1", maxExprDepth-1, got, err)
+	}
+}
+
+func TestMathExpressionHandler(t *testing.T) {
+	r := newRouter()
+	w := postJSON(r, "/math/expression", `{"expr":"(1 + 2) * 3"}`)
+	if w.Code != http.StatusOK || decodeBody(t, w)["result"] != 9.0 {
+		t.Errorf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	w = postJSON(r, "/math/expression", `{"expr":"1 / 0"}`)
+	if w.Code != http.StatusBadRequest || errorCode(t, w) != "invalid_expression" {
+		t.Fatalf("division by zero: status = %d, body %s", w.Code, w.Body)
+	}
+	details, _ := decodeBody(t, w)["error"].(map[string]interface{})["details"].(map[string]interface{})
+	if details["position"] != 2.0 {
+		t.Errorf("details = %v, want position 2", details)
+	}
+
+	big := "1" + strings.Repeat("0", 300)
+	if w := postJSON(r, "/math/expression", `{"expr":"`+big+` * `+big+`"}`); w.Code != http.StatusBadRequest || errorCode(t, w) != "non_finite_result" {
+		t.Errorf("overflow: status = %d, body %s, want 400 non_finite_result", w.Code, w.Body)
+	}
+}
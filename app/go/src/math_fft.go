@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFFTSize caps how many samples /math/fft accepts.
+var maxFFTSize = 1 << 20
+
+// fft computes the discrete Fourier transform of x in place with an
+// iterative radix-2 Cooley-Tukey pass. len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range x {
+		if j := int(bits.Reverse64(uint64(i)) >> shift); i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := start; k < start+size/2; k++ {
+				t := w * x[k+size/2]
+				x[k], x[k+size/2] = x[k]+t, x[k]-t
+				w *= step
+			}
+		}
+	}
+}
+
+// magnitudeSpectrum zero-pads signal to the next power of two and returns
+// the magnitude of every FFT bin.
+func magnitudeSpectrum(signal []float64) []float64 {
+	n := 1
+	for n < len(signal) {
+		n <<= 1
+	}
+	x := make([]complex128, n)
+	for i, v := range signal {
+		x[i] = complex(v, 0)
+	}
+	fft(x)
+	magnitudes := make([]float64, n)
+	for i, v := range x {
+		magnitudes[i] = cmplx.Abs(v)
+	}
+	return magnitudes
+}
+
+type mathFFTRequest struct {
+	Signal []float64 `json:"signal" binding:"required,min=1"`
+}
+
+type mathFFTResponse struct {
+	Magnitudes []float64 `json:"magnitudes"`
+}
+
+// mathFFTHandler returns the magnitude spectrum of signal. Inputs whose
+// length is not a power of two are zero-padded up to one, so the response
+// may hold more bins than the input had samples.
+func mathFFTHandler(c *gin.Context) {
+	var payload mathFFTRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if len(payload.Signal) > maxFFTSize {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("signal must have at most %d samples", maxFFTSize))
+		return
+	}
+
+	magnitudes := magnitudeSpectrum(payload.Signal)
+	for _, m := range magnitudes {
+		if math.IsInf(m, 0) || math.IsNaN(m) {
+			respondError(c, http.StatusBadRequest, errNotFinite)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, mathFFTResponse{Magnitudes: magnitudes})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+)
+
+// postFFT sends signal to /math/fft and returns the magnitude spectrum.
+func postFFT(t *testing.T, signal []float64) []float64 {
+	t.Helper()
+	body, _ := json.Marshal(mathFFTRequest{Signal: signal})
+	w := postJSON(newRouter(), "/math/fft", string(body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var got mathFFTResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	return got.Magnitudes
+}
+
+func TestMathFFTSinePeak(t *testing.T) {
+	const n, bin = 64, 5
+	signal := make([]float64, n)
+	for i := range signal {
+		signal[i] = math.Sin(2 * math.Pi * bin * float64(i) / n)
+	}
+
+	magnitudes := postFFT(t, signal)
+	if len(magnitudes) != n {
+		t.Fatalf("got %d bins, want %d", len(magnitudes), n)
+	}
+	peak := 0
+	for i := 1; i < n/2; i++ {
+		if magnitudes[i] > magnitudes[peak] {
+			peak = i
+		}
+	}
+	if peak != bin {
+		t.Errorf("peak at bin %d, want %d", peak, bin)
+	}
+	// A unit sine splits its energy between bin and its mirror, n/2 each.
+	if got := magnitudes[bin]; math.Abs(got-n/2) > 1e-9 {
+		t.Errorf("magnitude at bin %d = %v, want %d", bin, got, n/2)
+	}
+	if got := magnitudes[n-bin]; math.Abs(got-n/2) > 1e-9 {
+		t.Errorf("mirror magnitude = %v, want %d", got, n/2)
+	}
+}
+
+func TestMathFFTPadding(t *testing.T) {
+	if got := len(postFFT(t, []float64{1, 2, 3, 4, 5})); got != 8 {
+		t.Errorf("5 samples gave %d bins, want 8", got)
+	}
+	if w := postJSON(newRouter(), "/math/fft", `{"signal":[]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("empty signal: status = %d, want 400", w.Code)
+	}
+}
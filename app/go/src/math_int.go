@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// intOperation folds a non-empty slice of integers, failing on overflow.
+type intOperation func(numbers []int64) (int64, error)
+
+var errIntegerOverflow = errors.New("integer overflow")
+
+// intOperations are the operations available in "int" mode.
+var intOperations = map[string]intOperation{
+	"sum":      intSum,
+	"product":  intProduct,
+	"subtract": intSubtract,
+}
+
+var intOperationNames = func() []string {
+	names := make([]string, 0, len(intOperations))
+	for name := range intOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+func (job mathJob) runInt() (interface{}, error) {
+	if len(job.IntNumbers) == 0 {
+		return nil, errors.New("numbers must not be empty")
+	}
+	operation, ok := intOperations[job.Operation]
+	if !ok {
+		return nil, errUnsupportedOperation
+	}
+	return operation(job.IntNumbers)
+}
+
+func intSum(numbers []int64) (int64, error) {
+	var result int64
+	for _, num := range numbers {
+		if (num > 0 && result > math.MaxInt64-num) || (num < 0 && result < math.MinInt64-num) {
+			return 0, errIntegerOverflow
+		}
+		result += num
+	}
+	return result, nil
+}
+
+func intProduct(numbers []int64) (int64, error) {
+	result := int64(1)
+	for _, num := range numbers {
+		if result == 0 || num == 0 {
+			result = 0
+			continue
+		}
+		product := result * num
+		if product/num != result || (result == -1 && num == math.MinInt64) || (num == -1 && result == math.MinInt64) {
+			return 0, errIntegerOverflow
+		}
+		result = product
+	}
+	return result, nil
+}
+
+func intSubtract(numbers []int64) (int64, error) {
+	result := numbers[0]
+	for _, num := range numbers[1:] {
+		if (num < 0 && result > math.MaxInt64+num) || (num > 0 && result < math.MinInt64+num) {
+			return 0, errIntegerOverflow
+		}
+		result -= num
+	}
+	return result, nil
+}
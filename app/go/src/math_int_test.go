@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMathIntOverflow(t *testing.T) {
+	r := newRouter()
+	for _, body := range []string{
+		`{"mode":"int","operation":"product","numbers":[4294967296,4294967296]}`,
+		`{"mode":"int","operation":"product","numbers":[-1,-9223372036854775808]}`,
+		`{"mode":"int","operation":"sum","numbers":[9223372036854775807,1]}`,
+		`{"mode":"int","operation":"subtract","numbers":[-9223372036854775808,1]}`,
+	} {
+		w := postJSON(r, "/math", body)
+		if w.Code != http.StatusBadRequest || errorMessage(decodeBody(t, w)) != errIntegerOverflow.Error() {
+			t.Errorf("%s: status = %d, body %s", body, w.Code, w.Body)
+		}
+	}
+
+	// Just below the limit the exact product comes back, with no float
+	// rounding.
+	w := postJSON(r, "/math", `{"mode":"int","operation":"product","numbers":[3037000499,3037000499]}`)
+	if got := strings.TrimSpace(w.Body.String()); got != `{"result":9223372030926249001}` {
+		t.Errorf("body = %s", got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// maxInterestPeriods caps periods on /math/interest; compounding keeps every
+// digit exactly, so cost grows with the period count.
+var maxInterestPeriods = 1200
+
+// interestAmount returns the final amount for principal at rate per period
+// over periods: principal*(1+rate*periods) for "simple" and
+// principal*(1+rate)^periods for "compound".
+func interestAmount(kind string, principal, rate decimal.Decimal, periods int) (decimal.Decimal, error) {
+	switch kind {
+	case "simple":
+		return principal.Mul(decimal.NewFromInt(1).Add(rate.Mul(decimal.NewFromInt(int64(periods))))), nil
+	case "compound":
+		growth, err := decimal.NewFromInt(1).Add(rate).PowInt32(int32(periods))
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		return principal.Mul(growth), nil
+	default:
+		return decimal.Decimal{}, &APIError{
+			Code:    "invalid_type",
+			Message: "unsupported type",
+			Details: map[string]interface{}{"supported": []string{"compound", "simple"}},
+		}
+	}
+}
+
+type mathInterestRequest struct {
+	Principal decimal.Decimal `json:"principal"`
+	Rate      decimal.Decimal `json:"rate"`
+	Periods   int             `json:"periods"`
+	Type      string          `json:"type" binding:"required"`
+}
+
+type mathInterestResponse struct {
+	Amount   string `json:"amount"`
+	Interest string `json:"interest"`
+}
+
+// mathInterestHandler computes simple or compound interest in exact decimal
+// arithmetic. Principal and rate may be JSON numbers or strings; rate is the
+// fraction per period (0.05 for 5%). Amounts are rounded to cents only at the
+// end and returned as strings so no precision is lost in transit.
+func mathInterestHandler(c *gin.Context) {
+	var payload mathInterestRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if payload.Principal.IsNegative() || payload.Rate.IsNegative() {
+		respondError(c, http.StatusBadRequest, errors.New("principal and rate must not be negative"))
+		return
+	}
+	if payload.Periods < 0 || payload.Periods > maxInterestPeriods {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("periods must be between 0 and %d", maxInterestPeriods))
+		return
+	}
+
+	amount, err := interestAmount(payload.Type, payload.Principal, payload.Rate, payload.Periods)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, mathInterestResponse{
+		Amount:   amount.StringFixed(2),
+		Interest: amount.Sub(payload.Principal).StringFixed(2),
+	})
+}
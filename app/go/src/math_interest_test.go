@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestMathInterest(t *testing.T) {
+	tests := []struct {
+		body             string
+		amount, interest string
+	}{
+		// 1000 * (1 + 0.05*3)
+		{`{"type":"simple","principal":1000,"rate":0.05,"periods":3}`, "1150.00", "150.00"},
+		// 1000 * 1.05^3 = 1157.625, rounded half away from zero
+		{`{"type":"compound","principal":1000,"rate":0.05,"periods":3}`, "1157.63", "157.63"},
+		// 100.10 * 1.1^2 = 121.121
+		{`{"type":"compound","principal":"100.10","rate":"0.1","periods":2}`, "121.12", "21.02"},
+		// 0.1 * (1 + 0.2*1) = 0.12 exactly, where float64 gives 0.12000000000000001
+		{`{"type":"simple","principal":0.1,"rate":0.2,"periods":1}`, "0.12", "0.02"},
+		{`{"type":"compound","principal":500,"rate":0.05,"periods":0}`, "500.00", "0.00"},
+	}
+	r := newRouter()
+	for _, tt := range tests {
+		w := postJSON(r, "/math/interest", tt.body)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.body, w.Code, w.Body)
+			continue
+		}
+		body := decodeBody(t, w)
+		if body["amount"] != tt.amount || body["interest"] != tt.interest {
+			t.Errorf("%s: amount %v interest %v, want %s and %s", tt.body, body["amount"], body["interest"], tt.amount, tt.interest)
+		}
+	}
+
+	for _, body := range []string{
+		`{"type":"simple","principal":-1,"rate":0.05,"periods":3}`,
+		`{"type":"simple","principal":1000,"rate":-0.05,"periods":3}`,
+		`{"type":"simple","principal":1000,"rate":0.05,"periods":-1}`,
+		fmt.Sprintf(`{"type":"simple","principal":1000,"rate":0.05,"periods":%d}`, maxInterestPeriods+1),
+		`{"type":"continuous","principal":1000,"rate":0.05,"periods":3}`,
+	} {
+		if w := postJSON(r, "/math/interest", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
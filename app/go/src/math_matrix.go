@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMatrixElements caps the combined element count of both inputs and the
+// result.
+const maxMatrixElements = 1_000_000
+
+// matrixShape returns the dimensions of m, rejecting empty or ragged input.
+func matrixShape(name string, m [][]float64) (rows, cols int, err error) {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return 0, 0, fmt.Errorf("matrix %s must not be empty", name)
+	}
+	cols = len(m[0])
+	for _, row := range m {
+		if len(row) != cols {
+			return 0, 0, fmt.Errorf("matrix %s rows must all have the same length", name)
+		}
+	}
+	return len(m), cols, nil
+}
+
+func multiplyMatrices(a, b [][]float64) ([][]float64, error) {
+	aRows, aCols, err := matrixShape("a", a)
+	if err != nil {
+		return nil, err
+	}
+	bRows, bCols, err := matrixShape("b", b)
+	if err != nil {
+		return nil, err
+	}
+	if aCols != bRows {
+		return nil, fmt.Errorf("dimension mismatch: a is %dx%d, b is %dx%d", aRows, aCols, bRows, bCols)
+	}
+	if aRows*aCols+bRows*bCols+aRows*bCols > maxMatrixElements {
+		return nil, errors.New("matrices too large")
+	}
+
+	result := make([][]float64, aRows)
+	for i := range result {
+		row := make([]float64, bCols)
+		// i-k-j order walks b row by row, which is friendlier to the cache.
+		for k, aik := range a[i] {
+			for j, bkj := range b[k] {
+				row[j] += aik * bkj
+			}
+		}
+		result[i] = row
+	}
+	return result, nil
+}
+
+type mathMatrixRequest struct {
+	A [][]float64 `json:"a"`
+	B [][]float64 `json:"b"`
+}
+
+type mathMatrixResponse struct {
+	Result [][]float64 `json:"result"`
+}
+
+func mathMatrixHandler(c *gin.Context) {
+	var payload mathMatrixRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	result, err := multiplyMatrices(payload.A, payload.B)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, mathMatrixResponse{Result: result})
+}
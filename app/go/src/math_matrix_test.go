@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMathMatrix(t *testing.T) {
+	r := newRouter()
+	w := postJSON(r, "/math/matrix", `{"a":[[1,2,3],[4,5,6]],"b":[[7,8],[9,10],[11,12]]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var got mathMatrixResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{58, 64}, {139, 154}}
+	if !reflect.DeepEqual(got.Result, want) {
+		t.Errorf("result = %v, want %v", got.Result, want)
+	}
+
+	for _, body := range []string{
+		`{"a":[[1,2,3],[4,5,6]],"b":[[1,2],[3,4]]}`,
+		`{"a":[[1,2],[3]],"b":[[1],[2]]}`,
+		`{"a":[],"b":[[1]]}`,
+	} {
+		if w := postJSON(r, "/math/matrix", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPrimesLimit caps the limit /math/primes accepts; the sieve allocates one
+// byte per candidate.
+var maxPrimesLimit = 10000000
+
+// sievePrimes returns every prime <= limit using a Sieve of Eratosthenes.
+func sievePrimes(limit int) []int {
+	if limit < 2 {
+		return []int{}
+	}
+	composite := make([]bool, limit+1)
+	for i := 2; i*i <= limit; i++ {
+		if composite[i] {
+			continue
+		}
+		for j := i * i; j <= limit; j += i {
+			composite[j] = true
+		}
+	}
+	primes := []int{}
+	for i := 2; i <= limit; i++ {
+		if !composite[i] {
+			primes = append(primes, i)
+		}
+	}
+	return primes
+}
+
+type mathPrimesRequest struct {
+	Limit     int  `json:"limit"`
+	CountOnly bool `json:"count_only"`
+}
+
+type mathPrimesResponse struct {
+	Count  int   `json:"count"`
+	Primes []int `json:"primes"`
+}
+
+// mathPrimesCountResponse answers a count_only request.
+type mathPrimesCountResponse struct {
+	Count int `json:"count"`
+}
+
+func mathPrimesHandler(c *gin.Context) {
+	var payload mathPrimesRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if payload.Limit < 0 || payload.Limit > maxPrimesLimit {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("limit must be between 0 and %d", maxPrimesLimit))
+		return
+	}
+
+	primes := sievePrimes(payload.Limit)
+	if payload.CountOnly {
+		c.JSON(http.StatusOK, mathPrimesCountResponse{Count: len(primes)})
+		return
+	}
+	c.JSON(http.StatusOK, mathPrimesResponse{Count: len(primes), Primes: primes})
+}
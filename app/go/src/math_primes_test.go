@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMathPrimes(t *testing.T) {
+	r := newRouter()
+	w := postJSON(r, "/math/primes", `{"limit":30}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var got mathPrimesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []int{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}
+	if got.Count != len(want) || !reflect.DeepEqual(got.Primes, want) {
+		t.Errorf("got %d primes %v, want %v", got.Count, got.Primes, want)
+	}
+
+	for limit, count := range map[int]float64{0: 0, 1: 0, 2: 1, 10: 4, 100: 25, 1000: 168, 1000000: 78498} {
+		w := postJSON(r, "/math/primes", fmt.Sprintf(`{"limit":%d,"count_only":true}`, limit))
+		if w.Code != http.StatusOK {
+			t.Errorf("limit %d: status = %d", limit, w.Code)
+			continue
+		}
+		body := decodeBody(t, w)
+		if body["count"] != count {
+			t.Errorf("pi(%d) = %v, want %v", limit, body["count"], count)
+		}
+		if _, ok := body["primes"]; ok {
+			t.Errorf("limit %d: count_only response includes primes", limit)
+		}
+	}
+
+	for _, body := range []string{`{"limit":-1}`, fmt.Sprintf(`{"limit":%d}`, maxPrimesLimit+1)} {
+		if w := postJSON(r, "/math/primes", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
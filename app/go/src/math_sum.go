@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// summations are the strategies the "summation" field selects for sum.
+// Floating-point addition isn't associative, so they can disagree.
+var summations = map[string]mathOperation{
+	"naive":  sum,
+	"sorted": sortedSum,
+	"kahan":  kahanSum,
+}
+
+// sortedSum adds numbers in order of increasing magnitude, so small terms
+// are accumulated before they'd be swamped by large ones.
+func sortedSum(numbers []float64) (float64, error) {
+	sorted := append([]float64(nil), numbers...)
+	sort.Slice(sorted, func(i, j int) bool { return math.Abs(sorted[i]) < math.Abs(sorted[j]) })
+	return sum(sorted)
+}
+
+// kahanSum carries a running compensation for the low-order bits each
+// addition loses (Neumaier's variant, which also handles terms larger than
+// the running sum).
+func kahanSum(numbers []float64) (float64, error) {
+	var result, compensation float64
+	for _, num := range numbers {
+		t := result + num
+		if math.Abs(result) >= math.Abs(num) {
+			compensation += (result - t) + num
+		} else {
+			compensation += (num - t) + result
+		}
+		result = t
+	}
+	return result + compensation, nil
+}
+
+// summation returns the strategy the job uses for sum.
+func (job mathJob) summation() string {
+	if job.Summation == "" {
+		return "naive"
+	}
+	return job.Summation
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMathSummation(t *testing.T) {
+	tenths := strings.TrimSuffix(strings.Repeat("0.1,", 10), ",")
+	tests := []struct {
+		numbers string
+		want    float64
+	}{
+		{tenths, 1},
+		{"1,1e100,1,-1e100", 2},
+	}
+	r := newRouter()
+	result := func(numbers, summation string) float64 {
+		t.Helper()
+		w := postJSON(r, "/math", fmt.Sprintf(`{"operation":"sum","numbers":[%s],"summation":%q}`, numbers, summation))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", summation, w.Code, w.Body)
+		}
+		if got := w.Header().Get("X-Summation"); got != summation {
+			t.Errorf("X-Summation = %q, want %q", got, summation)
+		}
+		result, _ := decodeBody(t, w)["result"].(float64)
+		return result
+	}
+	for _, tt := range tests {
+		naive, kahan := result(tt.numbers, "naive"), result(tt.numbers, "kahan")
+		if kahan != tt.want {
+			t.Errorf("[%s]: kahan = %v, want %v", tt.numbers, kahan, tt.want)
+		}
+		if math.Abs(naive-tt.want) <= math.Abs(kahan-tt.want) {
+			t.Errorf("[%s]: naive %v is no further from %v than kahan %v", tt.numbers, naive, tt.want, kahan)
+		}
+	}
+
+	if w := postJSON(r, "/math", `{"operation":"sum","numbers":[1],"summation":"pairwise"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown summation: status = %d, want 400", w.Code)
+	}
+	if w := postJSON(r, "/math", `{"operation":"mean","numbers":[1],"summation":"kahan"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("summation on mean: status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// postMath sends body to /math and returns the status and decoded body.
+func postMath(t *testing.T, body string) (int, map[string]interface{}) {
+	t.Helper()
+	w := postJSON(newRouter(), "/math", body)
+	return w.Code, decodeBody(t, w)
+}
+
+// errorMessage returns the message of an error envelope body.
+func errorMessage(body map[string]interface{}) string {
+	apiErr, _ := body["error"].(map[string]interface{})
+	message, _ := apiErr["message"].(string)
+	return message
+}
+
+func TestMathStrictEmptyNumbers(t *testing.T) {
+	tests := []struct {
+		body    string
+		message string
+	}{
+		{`{"operation":"sum","numbers":[]}`, "numbers must not be empty"},
+		{`{"operation":"product","numbers":[],"strict":true}`, "numbers must not be empty for operation product"},
+		{`{"operation":"nope","numbers":[],"strict":true}`, "numbers must not be empty for operation nope"},
+		{`{"operation":"sum","numbers":[],"mode":"int","strict":true}`, "numbers must not be empty for operation sum"},
+	}
+	for _, tt := range tests {
+		status, body := postMath(t, tt.body)
+		if status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", tt.body, status)
+		}
+		if got := errorMessage(body); got != tt.message {
+			t.Errorf("%s: message = %q, want %q", tt.body, got, tt.message)
+		}
+	}
+}
+
+func TestMathMinMax(t *testing.T) {
+	tests := []struct {
+		body string
+		want float64
+	}{
+		{`{"operation":"min","numbers":[7]}`, 7},
+		{`{"operation":"max","numbers":[7]}`, 7},
+		{`{"operation":"min","numbers":[-3,-10.5,4]}`, -10.5},
+		{`{"operation":"max","numbers":[-3,-10.5,-1]}`, -1},
+	}
+	for _, tt := range tests {
+		status, body := postMath(t, tt.body)
+		if status != http.StatusOK {
+			t.Errorf("%s: status = %d, body %v", tt.body, status, body)
+			continue
+		}
+		if got := body["result"]; got != tt.want {
+			t.Errorf("%s: result = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestMathPowerModulo(t *testing.T) {
+	tests := []struct {
+		body string
+		want float64
+	}{
+		{`{"operation":"power","numbers":[4,0.5]}`, 2},
+		{`{"operation":"power","numbers":[8,-0.5]}`, 0.35355339059327373},
+		{`{"operation":"power","numbers":[-2,3]}`, -8},
+		{`{"operation":"power","numbers":[-2,2]}`, 4},
+		{`{"operation":"modulo","numbers":[7.5,2]}`, 1.5},
+		{`{"operation":"modulo","numbers":[-7,3]}`, -1},
+	}
+	for _, tt := range tests {
+		status, body := postMath(t, tt.body)
+		if status != http.StatusOK {
+			t.Errorf("%s: status = %d, body %v", tt.body, status, body)
+			continue
+		}
+		if got := body["result"]; got != tt.want {
+			t.Errorf("%s: result = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ body, message string }{
+		{`{"operation":"modulo","numbers":[5,0]}`, "modulo by zero"},
+		{`{"operation":"modulo","numbers":[5]}`, "modulo requires exactly two operands: dividend and divisor"},
+		{`{"operation":"power","numbers":[2,3,4]}`, "power requires exactly two operands: base and exponent"},
+		{`{"operation":"power","numbers":[-8,0.5]}`, errNotFinite.Error()},
+	} {
+		status, body := postMath(t, tt.body)
+		if status != http.StatusBadRequest || errorMessage(body) != tt.message {
+			t.Errorf("%s: status = %d, message %q, want 400 %q", tt.body, status, errorMessage(body), tt.message)
+		}
+	}
+}
+
+func TestMathNotFinite(t *testing.T) {
+	for _, body := range []string{
+		`{"operation":"product","numbers":[1e200,1e200]}`,
+		`{"operation":"product","numbers":[-1e200,1e200]}`,
+		`{"operation":"sum","numbers":[1.7e308,1.7e308]}`,
+	} {
+		status, resp := postMath(t, body)
+		if status != http.StatusBadRequest || errorMessage(resp) != "result is not finite" {
+			t.Errorf("%s: status = %d, body %v, want 400 result is not finite", body, status, resp)
+		}
+	}
+}
+
+func TestMathPrecision(t *testing.T) {
+	tests := []struct {
+		body string
+		want float64
+	}{
+		{`{"operation":"divide","numbers":[1,3],"precision":2}`, 0.33},
+		{`{"operation":"divide","numbers":[2,3],"precision":2}`, 0.67},
+		{`{"operation":"divide","numbers":[-2,3],"precision":0}`, -1},
+		{`{"operation":"divide","numbers":[1,3]}`, 1.0 / 3},
+		{`{"operation":"mean","numbers":[0,0,1],"precision":15}`, 0.333333333333333},
+	}
+	for _, tt := range tests {
+		status, body := postMath(t, tt.body)
+		if status != http.StatusOK {
+			t.Errorf("%s: status = %d, body %v", tt.body, status, body)
+			continue
+		}
+		if got := body["result"]; got != tt.want {
+			t.Errorf("%s: result = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+
+	for _, body := range []string{
+		`{"operation":"divide","numbers":[1,3],"precision":-1}`,
+		`{"operation":"divide","numbers":[1,3],"precision":16}`,
+	} {
+		if status, _ := postMath(t, body); status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, status)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func dotProduct(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("dimension mismatch: a has %d elements, b has %d", len(a), len(b))
+	}
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum, nil
+}
+
+func crossProduct(a, b []float64) ([]float64, error) {
+	if len(a) != 3 || len(b) != 3 {
+		return nil, errors.New("cross product requires vectors of length 3")
+	}
+	return []float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}, nil
+}
+
+type mathVectorRequest struct {
+	A         []float64 `json:"a" binding:"required"`
+	B         []float64 `json:"b" binding:"required"`
+	Operation string    `json:"operation" binding:"required"`
+}
+
+// mathVectorResponse's Result is a number for dot and a vector for cross.
+type mathVectorResponse struct {
+	Result interface{} `json:"result"`
+}
+
+func mathVectorHandler(c *gin.Context) {
+	var payload mathVectorRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	var result interface{}
+	var err error
+	switch payload.Operation {
+	case "dot":
+		result, err = dotProduct(payload.A, payload.B)
+	case "cross":
+		result, err = crossProduct(payload.A, payload.B)
+	default:
+		respondError(c, http.StatusBadRequest, &APIError{
+			Code:    "invalid_operation",
+			Message: "unsupported operation",
+			Details: map[string]interface{}{"supported": []string{"cross", "dot"}},
+		})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, mathVectorResponse{Result: result})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMathVector(t *testing.T) {
+	r := newRouter()
+	tests := []struct {
+		body string
+		want interface{}
+	}{
+		{`{"operation":"cross","a":[1,0,0],"b":[0,1,0]}`, []interface{}{0.0, 0.0, 1.0}},
+		{`{"operation":"cross","a":[1,2,3],"b":[4,5,6]}`, []interface{}{-3.0, 6.0, -3.0}},
+		{`{"operation":"dot","a":[1,2,3],"b":[4,5,6]}`, 32.0},
+	}
+	for _, tt := range tests {
+		w := postJSON(r, "/math/vector", tt.body)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.body, w.Code, w.Body)
+			continue
+		}
+		if got := decodeBody(t, w)["result"]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: result = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+
+	for _, body := range []string{
+		`{"operation":"dot","a":[1,2,3],"b":[1,2]}`,
+		`{"operation":"cross","a":[1,2],"b":[3,4]}`,
+		`{"operation":"cross","a":[1,2,3,4],"b":[1,2,3,4]}`,
+	} {
+		if w := postJSON(r, "/math/vector", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
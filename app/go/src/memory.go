@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMemoryMB caps a single /memory allocation.
+var maxMemoryMB = 512
+
+type memoryRequest struct {
+	MB    int  `json:"mb"`
+	Touch bool `json:"touch"`
+}
+
+type memoryResponse struct {
+	AllocatedMB int `json:"allocated_mb"`
+}
+
+func memoryHandler(c *gin.Context) {
+	var payload memoryRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if payload.MB < 0 || payload.MB > maxMemoryMB {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("mb must be between 0 and %d", maxMemoryMB))
+		return
+	}
+
+	buf := make([]byte, payload.MB<<20)
+	if payload.Touch {
+		// Writing one byte per page forces the OS to back it with real memory.
+		for i := 0; i < len(buf); i += os.Getpagesize() {
+			buf[i] = 1
+		}
+	}
+
+	c.JSON(http.StatusOK, memoryResponse{AllocatedMB: payload.MB})
+	// Hold the buffer until the response is written; it is garbage once the
+	// handler returns.
+	runtime.KeepAlive(buf)
+}
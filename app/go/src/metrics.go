@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricsRegistry = prometheus.NewRegistry()
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(requestsTotal, requestDuration)
+}
+
+// observeRequest records a finished request in the Prometheus metrics and the
+// /stats recorder. It is called from the timing middleware once the chain
+// returns, so the latency covers the whole response; X-Lambda-Duration is
+// stamped at the first write and reads lower for streamed bodies.
+func observeRequest(c *gin.Context, duration time.Duration) {
+	// FullPath is the route template, which keeps label cardinality bounded.
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	requestsTotal.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+	requestDuration.WithLabelValues(route).Observe(duration.Seconds())
+	latencies.record(route, duration)
+}
+
+var metricsHandler = gin.WrapH(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// requestCount scrapes /metrics from h and returns the http_requests_total
+// sample for the given labels, or 0 if there is none.
+func requestCount(t *testing.T, h http.Handler, route, method, status string) float64 {
+	t.Helper()
+	w := serve(h, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("/metrics status = %d", w.Code)
+	}
+	series := fmt.Sprintf(`http_requests_total{method=%q,route=%q,status=%q} `, method, route, status)
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series); ok {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("bad sample %q", line)
+			}
+			return n
+		}
+	}
+	return 0
+}
+
+func TestMetricsLabels(t *testing.T) {
+	r := newRouter()
+	tests := []struct {
+		req                   *http.Request
+		route, method, status string
+	}{
+		{httptest.NewRequest(http.MethodPost, "/math", strings.NewReader(`{"operation":"sum","numbers":[1]}`)), "/math", "POST", "200"},
+		{httptest.NewRequest(http.MethodPost, "/math", strings.NewReader(`{"operation":"cube","numbers":[1]}`)), "/math", "POST", "400"},
+		{httptest.NewRequest(http.MethodPost, "/fibonacci", strings.NewReader(`{"n":10}`)), "/fibonacci", "POST", "200"},
+		{httptest.NewRequest(http.MethodGet, "/no/such/route", nil), "unmatched", "GET", "404"},
+	}
+	for _, tt := range tests {
+		before := requestCount(t, r, tt.route, tt.method, tt.status)
+		tt.req.Header.Set("Content-Type", "application/json")
+		serve(r, tt.req)
+		if got := requestCount(t, r, tt.route, tt.method, tt.status); got != before+1 {
+			t.Errorf("%s %s: http_requests_total{route=%q,status=%q} = %v, want %v", tt.method, tt.req.URL.Path, tt.route, tt.status, got, before+1)
+		}
+	}
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `http_request_duration_seconds_count{route="/math"}`) {
+		t.Error("no latency histogram for /math")
+	}
+}
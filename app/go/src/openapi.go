@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeDoc describes one route in the OpenAPI document. Request and
+// Response, when set, are values of the route's JSON body types, from which
+// the schemas are generated; a oneOf Response lists alternative shapes.
+// RequestType and ResponseType name a non-JSON media type the route accepts
+// or serves, documented as an opaque binary body.
+type routeDoc struct {
+	Summary      string
+	Request      interface{}
+	Response     interface{}
+	RequestType  string
+	ResponseType string
+}
+
+// oneOf is a Response whose body takes exactly one of several shapes.
+type oneOf []interface{}
+
+var routeDocs = map[string]routeDoc{
+	"GET /health":           {Summary: "Liveness probe", Response: healthResponse{}},
+	"GET /version":          {Summary: "Build metadata", Response: versionResponse{}},
+	"GET /metrics":          {Summary: "Prometheus metrics", ResponseType: "text/plain"},
+	"GET /stats":            {Summary: "Per-route latency percentiles", Response: map[string]routeStats{}},
+	"GET /debug/memstats":   {Summary: "Go runtime memory statistics", Response: memStatsResponse{}},
+	"POST /debug/gc":        {Summary: "Force a garbage collection", Response: gcResponse{}},
+	"GET /openapi.json":     {Summary: "This document", Response: map[string]interface{}{}},
+	"GET /routes":           {Summary: "Registered routes and methods", Response: []routeInfo{}},
+	"POST /math":            {Summary: "Apply an operation to a list of numbers", Request: mathJob{}, Response: mathResponse{}},
+	"POST /math/batch":      {Summary: "Run many /math jobs concurrently", Request: mathBatchRequest{}, Response: mathBatchResponse{}, ResponseType: "application/x-ndjson"},
+	"POST /math/expression": {Summary: "Evaluate an arithmetic expression", Request: mathExpressionRequest{}, Response: mathExpressionResponse{}},
+	"POST /math/fft":        {Summary: "Magnitude spectrum of a signal via radix-2 FFT", Request: mathFFTRequest{}, Response: mathFFTResponse{}},
+	"POST /math/interest":   {Summary: "Simple or compound interest in decimal arithmetic", Request: mathInterestRequest{}, Response: mathInterestResponse{}},
+	"POST /math/matrix":     {Summary: "Multiply two matrices", Request: mathMatrixRequest{}, Response: mathMatrixResponse{}},
+	"POST /math/primes":     {Summary: "Primes up to a limit via a sieve", Request: mathPrimesRequest{}, Response: oneOf{mathPrimesResponse{}, mathPrimesCountResponse{}}},
+	"POST /math/vector":     {Summary: "Dot or cross product of two vectors", Request: mathVectorRequest{}, Response: mathVectorResponse{}},
+	"POST /json":            {Summary: "Marshal a single key/value pair", Request: jsonPairRequest{}, Response: jsonDataResponse{}},
+	"POST /json/echo":       {Summary: "Re-marshal an arbitrary JSON object", Request: map[string]interface{}{}, Response: jsonDataResponse{}},
+	"POST /json/flatten":    {Summary: "Flatten a nested object to dot-path keys", Request: map[string]interface{}{}, Response: map[string]interface{}{}},
+	"POST /json/merge":      {Summary: "Merge objects shallowly or deeply", Request: jsonMergeRequest{}, Response: jsonMergeResponse{}},
+	"POST /json/select":     {Summary: "Extract values by JSONPath expression", Request: jsonSelectRequest{}, Response: jsonSelectResponse{}},
+	"POST /json/validate":   {Summary: "Validate a document against a JSON Schema", Request: jsonValidateRequest{}, Response: jsonValidateResponse{}},
+	"POST /string":          {Summary: "Match, replace or transform text", Request: stringRequest{}, Response: oneOf{stringMatchesResponse{}, stringResultResponse{}, stringGroupsResponse{}, stringLengthResponse{}}},
+	"POST /string/split":    {Summary: "Split text by a pattern or separator", Request: stringSplitRequest{}, Response: stringSplitResponse{}},
+	"POST /compress":        {Summary: "Compress text with a chosen algorithm", Request: compressRequest{}, Response: compressResponse{}, ResponseType: "application/octet-stream"},
+	"POST /compress/stream": {Summary: "Gzip the raw request body as a stream", RequestType: "application/octet-stream", ResponseType: "application/gzip"},
+	"POST /decompress":      {Summary: "Decompress a gzip request body", RequestType: "application/gzip", Response: decompressResponse{}},
+	"POST /hash":            {Summary: "Hex digest of text", Request: hashRequest{}, Response: hashResponse{}},
+	"POST /hmac/sign":       {Summary: "Compute an HMAC", Request: hmacRequest{}, Response: hmacSignResponse{}},
+	"POST /hmac/verify":     {Summary: "Verify an HMAC in constant time", Request: hmacVerifyRequest{}, Response: hmacVerifyResponse{}},
+	"POST /jwt/sign":        {Summary: "Sign claims as an HS256 JWT", Request: jwtSignRequest{}, Response: jwtSignResponse{}},
+	"POST /jwt/verify":      {Summary: "Verify an HS256 JWT", Request: jwtVerifyRequest{}, Response: jwtVerifyResponse{}},
+	"POST /proxy":           {Summary: "Fetch an allowlisted upstream URL", Request: upstreamRequest{}, Response: upstreamResult{}},
+	"POST /aggregate":       {Summary: "Fetch several upstreams concurrently", Request: aggregateRequest{}, Response: aggregateResponse{}},
+	"POST /encode":          {Summary: "Encode data as base64, base32 or hex", Request: codecRequest{}, Response: encodeResponse{}},
+	"POST /decode":          {Summary: "Decode base64, base32 or hex data", Request: codecRequest{}, Response: decodeResponse{}},
+	"POST /image":           {Summary: "Render or resize an image", Request: imageRequest{}, Response: imageResponse{}, ResponseType: "image/*"},
+	"POST /fibonacci":       {Summary: "Compute the nth Fibonacci number", Request: fibonacciRequest{}, Response: fibonacciResponse{}},
+	"POST /random":          {Summary: "Seeded pseudo-random numbers", Request: randomRequest{}, Response: randomResponse{}},
+	"POST /convert":         {Summary: "Convert an amount using the loaded rate table", Request: convertRequest{}, Response: convertResponse{}},
+	"POST /sleep":           {Summary: "Sleep for a number of milliseconds", Request: sleepRequest{}, Response: sleepResponse{}},
+	"POST /warmup":          {Summary: "Run lazy initialization ahead of traffic", Response: warmupResponse{}},
+	"POST /memory":          {Summary: "Allocate and hold memory", Request: memoryRequest{}, Response: memoryResponse{}},
+	"GET /echo":             {Summary: "Reflect the request method, headers and query", Response: echoResponse{}},
+	"POST /echo":            {Summary: "Reflect the request method, headers, query and body", RequestType: "application/octet-stream", Response: echoResponse{}},
+}
+
+// openAPIPath converts a gin route path to OpenAPI form, e.g. /x/:id to
+// /x/{id}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaOf derives a JSON Schema from a Go type, using json tag names and
+// treating binding:"required" fields as required. Types that marshal
+// themselves are strings if they are text marshalers and unconstrained
+// otherwise.
+func schemaOf(t reflect.Type) map[string]interface{} {
+	if t.Implements(textMarshalerType) {
+		return map[string]interface{}{"type": "string"}
+	}
+	if t.Implements(jsonMarshalerType) {
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		var required []string
+		addStructFields(t, properties, &required)
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// addStructFields adds t's fields to properties, promoting the fields of
+// untagged embedded structs the way encoding/json does.
+func addStructFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				*required = append(*required, name)
+			}
+		}
+	}
+}
+
+// responseSchema is the schema of a routeDoc Response.
+func responseSchema(response interface{}) map[string]interface{} {
+	alternatives, ok := response.(oneOf)
+	if !ok {
+		return schemaOf(reflect.TypeOf(response))
+	}
+	schemas := make([]interface{}, len(alternatives))
+	for i, alternative := range alternatives {
+		schemas[i] = schemaOf(reflect.TypeOf(alternative))
+	}
+	return map[string]interface{}{"oneOf": schemas}
+}
+
+// binarySchema documents a body that isn't JSON.
+var binarySchema = map[string]interface{}{"type": "string", "format": "binary"}
+
+// buildOpenAPI describes every route registered on r.
+func buildOpenAPI(routes gin.RoutesInfo) map[string]interface{} {
+	paths := map[string]interface{}{}
+	for _, route := range listRoutes(routes, true) {
+		path := openAPIPath(route.Path)
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+
+		doc := routeDocs[route.Method+" "+route.Path]
+		ok200 := map[string]interface{}{"description": "OK"}
+		content := map[string]interface{}{}
+		if doc.Response != nil {
+			content["application/json"] = map[string]interface{}{"schema": responseSchema(doc.Response)}
+		}
+		if doc.ResponseType != "" {
+			content[doc.ResponseType] = map[string]interface{}{"schema": binarySchema}
+		}
+		if len(content) > 0 {
+			ok200["content"] = content
+		}
+		operation := map[string]interface{}{
+			"responses": map[string]interface{}{
+				"200": ok200,
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": schemaOf(reflect.TypeOf(errorResponse{}))},
+					},
+				},
+			},
+		}
+		if doc.Summary != "" {
+			operation["summary"] = doc.Summary
+		}
+		requestContent := map[string]interface{}{}
+		if doc.Request != nil {
+			requestContent["application/json"] = map[string]interface{}{"schema": schemaOf(reflect.TypeOf(doc.Request))}
+		}
+		if doc.RequestType != "" {
+			requestContent[doc.RequestType] = map[string]interface{}{"schema": binarySchema}
+		}
+		if len(requestContent) > 0 {
+			operation["requestBody"] = map[string]interface{}{"required": true, "content": requestContent}
+		}
+		var parameters []interface{}
+		for _, segment := range strings.Split(route.Path, "/") {
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				parameters = append(parameters, map[string]interface{}{
+					"name": segment[1:], "in": "path", "required": true,
+					"schema": map[string]interface{}{"type": "string"},
+				})
+			}
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": "bff-lambda-benchmark", "version": "1.0.0"},
+		"paths":   paths,
+	}
+}
+
+// openAPIHandler serves the OpenAPI document for r, built on first request
+// so that it covers routes registered after this one.
+func openAPIHandler(r *gin.Engine) gin.HandlerFunc {
+	var once sync.Once
+	var doc map[string]interface{}
+	return func(c *gin.Context) {
+		once.Do(func() { doc = buildOpenAPI(r.Routes()) })
+		c.JSON(http.StatusOK, doc)
+	}
+}
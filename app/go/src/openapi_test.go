@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	r := newRouter()
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var doc struct {
+		OpenAPI string                                       `json:"openapi"`
+		Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("document is not valid JSON: %v", err)
+	}
+	if doc.OpenAPI == "" {
+		t.Error("openapi version missing")
+	}
+
+	for _, route := range r.Routes() {
+		operation, ok := doc.Paths[openAPIPath(route.Path)][strings.ToLower(route.Method)]
+		if !ok {
+			t.Errorf("%s %s missing from the document", route.Method, route.Path)
+			continue
+		}
+		if strings.HasPrefix(route.Path, "/debug/pprof") {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		routeDoc, ok := routeDocs[key]
+		if !ok {
+			t.Errorf("%s has no routeDocs entry", key)
+			continue
+		}
+		if routeDoc.Response == nil && routeDoc.ResponseType == "" {
+			t.Errorf("%s has no response schema", key)
+		}
+		if route.Method == http.MethodPost && key != "POST /warmup" && key != "POST /debug/gc" && operation["requestBody"] == nil {
+			t.Errorf("%s has no request body schema", key)
+		}
+	}
+
+	registered := map[string]bool{}
+	for _, route := range r.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for key := range routeDocs {
+		if !registered[key] {
+			t.Errorf("routeDocs entry %s matches no registered route", key)
+		}
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := schemaOf(reflect.TypeOf(hmacVerifyRequest{}))
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, name := range []string{"text", "key", "mac"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("property %q missing from %v", name, properties)
+		}
+	}
+	if _, ok := properties["hmacRequest"]; ok {
+		t.Error("embedded struct not promoted")
+	}
+
+	amount := schemaOf(reflect.TypeOf(convertResponse{}))["properties"].(map[string]interface{})["amount"]
+	if got := amount.(map[string]interface{})["type"]; got != "string" {
+		t.Errorf("decimal amount type = %v, want string", got)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// outboundConfig holds the connection pooling tunables of outboundClient.
+type outboundConfig struct {
+	Timeout             time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+}
+
+var defaultOutboundConfig = outboundConfig{
+	Timeout:             proxyTimeout,
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 100,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// outboundClient is shared by every outbound call so connections to the
+// same upstream are pooled and reused across requests.
+var outboundClient = newOutboundClient(defaultOutboundConfig)
+
+func newOutboundClient(cfg outboundConfig) *http.Client {
+	return &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConns:        cfg.MaxIdleConns,
+			MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+			IdleConnTimeout:     cfg.IdleConnTimeout,
+			DisableKeepAlives:   cfg.DisableKeepAlives,
+		},
+		CheckRedirect: checkRedirect,
+	}
+}
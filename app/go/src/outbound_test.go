@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOutboundKeepAlive(t *testing.T) {
+	tests := []struct {
+		disableKeepAlives bool
+		wantConns         int64
+	}{
+		{false, 1},
+		{true, 5},
+	}
+	for _, tt := range tests {
+		var conns atomic.Int64
+		upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		upstream.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				conns.Add(1)
+			}
+		}
+		upstream.Start()
+
+		cfg := defaultOutboundConfig
+		cfg.DisableKeepAlives = tt.disableKeepAlives
+		saved := outboundClient
+		outboundClient = newOutboundClient(cfg)
+
+		for i := 0; i < 5; i++ {
+			if _, err := fetchUpstream(context.Background(), http.MethodGet, upstream.URL); err != nil {
+				t.Fatal(err)
+			}
+		}
+		outboundClient.CloseIdleConnections()
+		outboundClient = saved
+		upstream.Close()
+
+		if got := conns.Load(); got != tt.wantConns {
+			t.Errorf("DisableKeepAlives=%t: %d connections for 5 requests, want %d", tt.disableKeepAlives, got, tt.wantConns)
+		}
+	}
+}
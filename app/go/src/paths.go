@@ -0,0 +1,19 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// foldPathCase lowercases the request path before it reaches h, so /Math
+// routes like /math. Every registered route is lowercase, so nothing becomes
+// unreachable. It is enabled with CASE_INSENSITIVE_ROUTES=true.
+func foldPathCase(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if lower := strings.ToLower(req.URL.Path); lower != req.URL.Path {
+			req.URL.Path = lower
+			req.URL.RawPath = ""
+		}
+		h.ServeHTTP(w, req)
+	})
+}
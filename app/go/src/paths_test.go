@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTrailingSlashRedirect(t *testing.T) {
+	w := postJSON(newRouter(), "/math/", `{"operation":"sum","numbers":[1,2]}`)
+	if w.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("status = %d, want 307", w.Code)
+	}
+	if got := w.Header().Get("Location"); got != "/math" {
+		t.Errorf("Location = %q, want /math", got)
+	}
+}
+
+func TestFoldPathCase(t *testing.T) {
+	sum := `{"operation":"sum","numbers":[1,2]}`
+	if w := postJSON(newRouter(), "/Math", sum); w.Code != http.StatusNotFound {
+		t.Errorf("strict routing: status = %d, want 404", w.Code)
+	}
+
+	h := foldPathCase(newRouter())
+	tests := []struct{ path, body string }{
+		{"/Math", sum},
+		{"/math", sum},
+		{"/MATH/Vector", `{"operation":"dot","a":[1,2],"b":[3,4]}`},
+	}
+	for _, tt := range tests {
+		if w := postJSON(h, tt.path, tt.body); w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.path, w.Code, w.Body)
+		}
+	}
+}
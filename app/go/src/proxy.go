@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// proxyTimeout bounds each outbound request, including reading the body.
+var proxyTimeout = 10 * time.Second
+
+// proxyAllowedHosts lists the hosts /proxy may reach, as host or host:port.
+// An empty list allows none.
+var proxyAllowedHosts = map[string]bool{}
+
+// checkRedirect applies the host allowlist to every redirect hop, so an
+// allowed upstream can't bounce the request to a disallowed one.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if !hostAllowed(req.URL) {
+		return errors.New("redirect to disallowed host: " + req.URL.Host)
+	}
+	return nil
+}
+
+// parseHostList splits a comma-separated host list into a set.
+func parseHostList(s string) map[string]bool {
+	hosts := make(map[string]bool)
+	for _, host := range strings.Split(s, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts[strings.ToLower(host)] = true
+		}
+	}
+	return hosts
+}
+
+// hostAllowed reports whether u targets an allowlisted host.
+func hostAllowed(u *url.URL) bool {
+	return proxyAllowedHosts[strings.ToLower(u.Host)] || proxyAllowedHosts[strings.ToLower(u.Hostname())]
+}
+
+var proxyMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// upstreamResult is what /proxy and /aggregate report for one upstream call.
+type upstreamResult struct {
+	Status    int     `json:"status,omitempty"`
+	Bytes     int64   `json:"bytes"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// fetchUpstream performs one outbound request and drains the body to count
+// it, so the connection can be reused.
+func fetchUpstream(ctx context.Context, method, target string) (upstreamResult, error) {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return upstreamResult{}, err
+	}
+	resp, err := outboundClient.Do(req)
+	if err != nil {
+		return upstreamResult{LatencyMs: msSince(start)}, err
+	}
+	defer resp.Body.Close()
+	n, err := io.Copy(io.Discard, resp.Body)
+	return upstreamResult{Status: resp.StatusCode, Bytes: n, LatencyMs: msSince(start)}, err
+}
+
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
+
+// checkUpstream validates a client-supplied upstream target, returning the
+// status and message to reject it with, or 0 if it is acceptable.
+func checkUpstream(method, target string) (int, string) {
+	if !proxyMethods[method] {
+		return http.StatusBadRequest, "unsupported method: " + method
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return http.StatusBadRequest, "url must be an absolute http or https URL"
+	}
+	if !hostAllowed(u) {
+		return http.StatusForbidden, "host not allowed: " + u.Host
+	}
+	return 0, ""
+}
+
+func proxyHandler(c *gin.Context) {
+	var payload upstreamRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if payload.Method == "" {
+		payload.Method = http.MethodGet
+	}
+	if status, msg := checkUpstream(payload.Method, payload.URL); status != 0 {
+		respondError(c, status, errors.New(msg))
+		return
+	}
+
+	result, err := fetchUpstream(c.Request.Context(), payload.Method, payload.URL)
+	if err != nil {
+		if respondIfCanceled(c) {
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) || isTimeout(err) {
+			respondError(c, http.StatusGatewayTimeout, newAPIError("upstream_timeout", "upstream timeout"))
+			return
+		}
+		respondError(c, http.StatusBadGateway, fmt.Errorf("upstream request failed: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// isTimeout reports whether err is a network timeout, such as the client's
+// own deadline firing.
+func isTimeout(err error) bool {
+	var timeoutErr interface{ Timeout() bool }
+	return errors.As(err, &timeoutErr) && timeoutErr.Timeout()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// allowUpstreams allowlists the hosts of servers for the rest of the test.
+func allowUpstreams(t *testing.T, servers ...*httptest.Server) {
+	t.Helper()
+	saved := proxyAllowedHosts
+	t.Cleanup(func() { proxyAllowedHosts = saved })
+	proxyAllowedHosts = map[string]bool{}
+	for _, srv := range servers {
+		u, err := url.Parse(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proxyAllowedHosts[u.Host] = true
+	}
+}
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://disallowed.example/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(strings.Repeat("x", 1234)))
+	}))
+	defer upstream.Close()
+	allowUpstreams(t, upstream)
+	r := newRouter()
+
+	w := postJSON(r, "/proxy", fmt.Sprintf(`{"url":%q}`, upstream.URL))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	body := decodeBody(t, w)
+	if body["status"] != float64(http.StatusTeapot) || body["bytes"] != 1234.0 {
+		t.Errorf("body = %v, want upstream status 418 and 1234 bytes", body)
+	}
+
+	if w := postJSON(r, "/proxy", `{"url":"http://disallowed.example/"}`); w.Code != http.StatusForbidden {
+		t.Errorf("disallowed host: status = %d, want 403", w.Code)
+	}
+	if w := postJSON(r, "/proxy", fmt.Sprintf(`{"url":%q}`, upstream.URL+"/redirect")); w.Code != http.StatusBadGateway {
+		t.Errorf("redirect to disallowed host: status = %d, want 502", w.Code)
+	}
+	if w := postJSON(r, "/proxy", `{"url":"file:///etc/passwd"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("non-http url: status = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queueLimiter serves at most slots requests at once. Excess requests wait
+// up to maxWait for a slot, with at most depth of them waiting; the rest and
+// those that time out get 503. Requests that had to wait report how long in
+// X-Queued-Ms.
+func queueLimiter(slots, depth int, maxWait time.Duration) gin.HandlerFunc {
+	sem := make(chan struct{}, slots)
+	var waiting atomic.Int64
+	return func(c *gin.Context) {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+			c.Next()
+			return
+		default:
+		}
+
+		if waiting.Add(1) > int64(depth) {
+			waiting.Add(-1)
+			c.Header("Retry-After", "1")
+			respondError(c, http.StatusServiceUnavailable, newAPIError("queue_full", "request queue is full"))
+			return
+		}
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(c.Request.Context(), maxWait)
+		defer cancel()
+		select {
+		case sem <- struct{}{}:
+			waiting.Add(-1)
+			defer func() { <-sem }()
+			c.Header("X-Queued-Ms", strconv.FormatInt(time.Since(start).Milliseconds(), 10))
+			c.Next()
+		case <-ctx.Done():
+			waiting.Add(-1)
+			if respondIfCanceled(c) {
+				return
+			}
+			c.Header("Retry-After", "1")
+			respondError(c, http.StatusServiceUnavailable, newAPIError("queue_timeout", "timed out waiting for a free slot"))
+		}
+	}
+}
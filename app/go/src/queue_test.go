@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// holdSlot occupies a queue slot with a /sleep of ms, returning once the
+// request is in flight and a func that waits for it to finish.
+func holdSlot(r http.Handler, ms int) (wait func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		postJSON(r, "/sleep", fmt.Sprintf(`{"ms":%d}`, ms))
+	}()
+	time.Sleep(30 * time.Millisecond)
+	return func() { <-done }
+}
+
+func TestQueueServedAfterWait(t *testing.T) {
+	t.Setenv("QUEUE_SLOTS", "1")
+	t.Setenv("QUEUE_MAX_WAIT_MS", "2000")
+	r := newRouter()
+
+	wait := holdSlot(r, 200)
+	defer wait()
+	w := postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	queued, err := strconv.Atoi(w.Header().Get("X-Queued-Ms"))
+	if err != nil || queued < 100 {
+		t.Errorf("X-Queued-Ms = %q, want the time spent behind the 200ms sleep", w.Header().Get("X-Queued-Ms"))
+	}
+
+	if w := postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`); w.Header().Get("X-Queued-Ms") != "" {
+		t.Error("X-Queued-Ms set on a request that found a free slot")
+	}
+}
+
+func TestQueueRejectedAfterWait(t *testing.T) {
+	t.Setenv("QUEUE_SLOTS", "1")
+	t.Setenv("QUEUE_MAX_WAIT_MS", "50")
+	r := newRouter()
+
+	wait := holdSlot(r, 300)
+	defer wait()
+	start := time.Now()
+	w := postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`)
+	if w.Code != http.StatusServiceUnavailable || errorCode(t, w) != "queue_timeout" {
+		t.Fatalf("status = %d, body %s, want 503 queue_timeout", w.Code, w.Body)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 250*time.Millisecond {
+		t.Errorf("rejected after %v, want about the 50ms max wait", elapsed)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get("X-Queued-Ms") != "" {
+		t.Errorf("headers = %v, want Retry-After and no X-Queued-Ms", w.Header())
+	}
+	if w.Header().Get("X-Lambda-Duration-Ms") == "" {
+		t.Error("rejection has no timing headers")
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	t.Setenv("QUEUE_SLOTS", "1")
+	t.Setenv("QUEUE_DEPTH", "1")
+	r := newRouter()
+
+	// The first sleep takes the slot and the second the only queue place.
+	wait := holdSlot(r, 200)
+	defer wait()
+	waitQueued := holdSlot(r, 0)
+	defer waitQueued()
+	if w := postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`); w.Code != http.StatusServiceUnavailable || errorCode(t, w) != "queue_full" {
+		t.Errorf("status = %d, body %s, want 503 queue_full", w.Code, w.Body)
+	}
+}
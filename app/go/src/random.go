@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRandomCount caps how many numbers /random generates per request.
+var maxRandomCount = 100000
+
+// randomNumbers returns count floats in [0, 1) from a source seeded with
+// seed, so equal seeds always give equal output.
+func randomNumbers(seed int64, count int) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	numbers := make([]float64, count)
+	for i := range numbers {
+		numbers[i] = rng.Float64()
+	}
+	return numbers
+}
+
+type randomRequest struct {
+	Count int   `json:"count"`
+	Seed  int64 `json:"seed"`
+}
+
+type randomResponse struct {
+	Numbers []float64 `json:"numbers"`
+}
+
+func randomHandler(c *gin.Context) {
+	var payload randomRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if payload.Count < 0 || payload.Count > maxRandomCount {
+		respondError(c, http.StatusBadRequest, fmt.Errorf("count must be between 0 and %d", maxRandomCount))
+		return
+	}
+	c.JSON(http.StatusOK, randomResponse{Numbers: randomNumbers(payload.Seed, payload.Count)})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRandomSeeded(t *testing.T) {
+	r := newRouter()
+	numbers := func(body string) []float64 {
+		t.Helper()
+		w := postJSON(r, "/random", body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", body, w.Code, w.Body)
+		}
+		var got randomResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Numbers
+	}
+
+	first := numbers(`{"seed":42,"count":50}`)
+	if len(first) != 50 {
+		t.Fatalf("got %d numbers, want 50", len(first))
+	}
+	if again := numbers(`{"seed":42,"count":50}`); !reflect.DeepEqual(first, again) {
+		t.Error("same seed produced different numbers")
+	}
+	if other := numbers(`{"seed":43,"count":50}`); reflect.DeepEqual(first, other) {
+		t.Error("different seeds produced the same numbers")
+	}
+
+	if w := postJSON(r, "/random", `{"seed":1,"count":100001}`); w.Code != http.StatusBadRequest {
+		t.Errorf("count over the cap: status = %d, want 400", w.Code)
+	}
+}
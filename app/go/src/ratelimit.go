@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// maxRateLimitedClients bounds the per-IP limiter table; when it fills up
+// the table is cleared, which at worst briefly refills every bucket.
+const maxRateLimitedClients = 10000
+
+// rateLimiter is a token bucket refilled at rps tokens per second holding at
+// most burst, shared by the whole server or kept per client IP.
+func rateLimiter(rps, burst int, perIP bool) gin.HandlerFunc {
+	reject := func(c *gin.Context) {
+		c.Header("Retry-After", "1")
+		respondError(c, http.StatusTooManyRequests, errors.New("rate limit exceeded"))
+	}
+
+	if !perIP {
+		limiter := rate.NewLimiter(rate.Limit(rps), burst)
+		return func(c *gin.Context) {
+			if !limiter.Allow() {
+				reject(c)
+				return
+			}
+			c.Next()
+		}
+	}
+
+	var mu sync.Mutex
+	limiters := make(map[string]*rate.Limiter)
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		mu.Lock()
+		limiter, ok := limiters[ip]
+		if !ok {
+			if len(limiters) >= maxRateLimitedClients {
+				clear(limiters)
+			}
+			limiter = rate.NewLimiter(rate.Limit(rps), burst)
+			limiters[ip] = limiter
+		}
+		mu.Unlock()
+		if !limiter.Allow() {
+			reject(c)
+			return
+		}
+		c.Next()
+	}
+}
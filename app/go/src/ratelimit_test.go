@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// burst sends n /math requests from remoteAddr and counts the outcomes.
+func burst(t *testing.T, h http.Handler, remoteAddr string, n int) (ok, limited int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/math", strings.NewReader(`{"operation":"sum","numbers":[1]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		w := serve(h, req)
+		switch w.Code {
+		case http.StatusOK:
+			ok++
+		case http.StatusTooManyRequests:
+			limited++
+			if got := w.Header().Get("Retry-After"); got == "" {
+				t.Error("429 without Retry-After")
+			}
+			if code := errorCode(t, w); code == "" {
+				t.Errorf("429 body %s is not an error envelope", w.Body)
+			}
+		default:
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+	}
+	return ok, limited
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Setenv("RATE_LIMIT_RPS", "1")
+	t.Setenv("RATE_LIMIT_BURST", "3")
+	r := newRouter()
+
+	before := requestCount(t, r, "/math", http.MethodPost, "429")
+	if ok, limited := burst(t, r, "192.0.2.1:1234", 10); ok != 3 || limited != 7 {
+		t.Errorf("%d served, %d limited; want 3 and 7", ok, limited)
+	}
+	if got := requestCount(t, r, "/math", http.MethodPost, "429"); got != before+7 {
+		t.Errorf("http_requests_total for 429s = %v, want %v", got, before+7)
+	}
+	// The bucket is shared, so another client finds it empty too.
+	if ok, _ := burst(t, r, "192.0.2.2:1234", 1); ok != 0 {
+		t.Error("second client served from the drained shared bucket")
+	}
+}
+
+func TestRateLimitPerIP(t *testing.T) {
+	t.Setenv("RATE_LIMIT_RPS", "1")
+	t.Setenv("RATE_LIMIT_BURST", "2")
+	t.Setenv("RATE_LIMIT_PER_IP", "true")
+	r := newRouter()
+
+	for _, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+		if ok, limited := burst(t, r, addr, 5); ok != 2 || limited != 3 {
+			t.Errorf("%s: %d served, %d limited; want 2 and 3", addr, ok, limited)
+		}
+	}
+}
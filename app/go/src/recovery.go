@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recoveryMiddleware turns a handler panic into a JSON 500 carrying the
+// request ID, logging the panic value and stack trace.
+func recoveryMiddleware(c *gin.Context) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			requestID := c.GetString(requestIDKey)
+			requestLogger(c).Error("panic recovered",
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			respondError(c, http.StatusInternalServerError, &APIError{
+				Code:    "internal_error",
+				Message: "internal server error",
+				Details: map[string]interface{}{"request_id": requestID},
+			})
+		}
+	}()
+	c.Next()
+}
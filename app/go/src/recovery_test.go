@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	useDefaultLogger(t, slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	r := newRouter()
+	r.GET("/panic", func(*gin.Context) { panic("boom") })
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", w.Code)
+	}
+	body := decodeBody(t, w)
+	apiErr, _ := body["error"].(map[string]interface{})
+	details, _ := apiErr["details"].(map[string]interface{})
+	if apiErr["code"] != "internal_error" || apiErr["message"] != "internal server error" {
+		t.Errorf("error = %v", apiErr)
+	}
+	if id := w.Header().Get(requestIDHeader); id == "" || details["request_id"] != id {
+		t.Errorf("details.request_id = %v, want header %q", details["request_id"], id)
+	}
+	if !strings.Contains(logs.String(), `"panic":"boom"`) || !strings.Contains(logs.String(), "recovery_test.go") {
+		t.Errorf("panic log lacks value or stack: %s", logs.String())
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDKey is the gin context key holding the current request's ID.
+const requestIDKey = "requestID"
+
+const requestIDHeader = "X-Request-ID"
+
+// requestIDMiddleware honors an incoming X-Request-ID or generates a UUID,
+// stores it under requestIDKey and echoes it on the response. It also attaches
+// a request-scoped logger carrying the ID; see requestLogger.
+func requestIDMiddleware(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if id == "" {
+		id = uuid.NewString()
+	}
+	c.Set(requestIDKey, id)
+	c.Set(loggerKey, slog.Default().With("request_id", id))
+	c.Header(requestIDHeader, id)
+	c.Next()
+}
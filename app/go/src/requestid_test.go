@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	r := newRouter()
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if _, err := uuid.Parse(w.Header().Get(requestIDHeader)); err != nil {
+		t.Errorf("generated %s = %q is not a UUID", requestIDHeader, w.Header().Get(requestIDHeader))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(requestIDHeader, "client-supplied")
+	if got := serve(r, req).Header().Get(requestIDHeader); got != "client-supplied" {
+		t.Errorf("echoed %s = %q, want client-supplied", requestIDHeader, got)
+	}
+}
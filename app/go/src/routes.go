@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type routeInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// listRoutes returns r's routes sorted by path then method, leaving out
+// /debug ones unless includeDebug is set.
+func listRoutes(routes gin.RoutesInfo, includeDebug bool) []routeInfo {
+	list := make([]routeInfo, 0, len(routes))
+	for _, route := range routes {
+		if !includeDebug && strings.HasPrefix(route.Path, "/debug/") {
+			continue
+		}
+		list = append(list, routeInfo{Method: route.Method, Path: route.Path})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Method < list[j].Method
+	})
+	return list
+}
+
+// routesHandler lists the routes registered on r. Debug routes are shown
+// only when pprof is enabled.
+func routesHandler(r *gin.Engine, includeDebug bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, listRoutes(r.Routes(), includeDebug))
+	}
+}
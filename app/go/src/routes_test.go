@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fetchRoutes returns the /routes listing of a fresh router.
+func fetchRoutes(t *testing.T) []routeInfo {
+	t.Helper()
+	w := serve(newRouter(), httptest.NewRequest(http.MethodGet, "/routes", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var routes []routeInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+func TestRoutes(t *testing.T) {
+	listed := map[routeInfo]bool{}
+	for _, route := range fetchRoutes(t) {
+		if strings.HasPrefix(route.Path, "/debug/") {
+			t.Errorf("%s %s listed with pprof disabled", route.Method, route.Path)
+		}
+		listed[route] = true
+	}
+	for _, want := range []routeInfo{
+		{http.MethodGet, "/health"},
+		{http.MethodGet, "/routes"},
+		{http.MethodPost, "/math"},
+		{http.MethodPost, "/json"},
+		{http.MethodPost, "/string"},
+		{http.MethodPost, "/compress"},
+		{http.MethodPost, "/image"},
+	} {
+		if !listed[want] {
+			t.Errorf("%s %s missing from /routes", want.Method, want.Path)
+		}
+	}
+
+	t.Setenv("ENABLE_PPROF", "true")
+	var debug bool
+	for _, route := range fetchRoutes(t) {
+		debug = debug || strings.HasPrefix(route.Path, "/debug/pprof")
+	}
+	if !debug {
+		t.Error("pprof routes missing from /routes with pprof enabled")
+	}
+}
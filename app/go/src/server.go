@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// shutdownGrace is how long in-flight requests get to finish on shutdown.
+var shutdownGrace = 10 * time.Second
+
+// Connection timeouts for the HTTP server. readHeaderTimeout is what cuts off
+// clients that trickle in headers; writeTimeout runs from the end of the
+// request headers, so it must outlast handlerTimeout.
+var (
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 90 * time.Second
+	idleTimeout       = 120 * time.Second
+)
+
+// serverHandler wraps the router for HTTP/2 cleartext when h2c is set;
+// otherwise the server speaks HTTP/1.1 only.
+func serverHandler(r http.Handler, h2cEnabled bool) http.Handler {
+	if !h2cEnabled {
+		return r
+	}
+	return h2c.NewHandler(r, &http2.Server{})
+}
+
+// runServer serves until ctx is canceled, then shuts srv down gracefully,
+// allowing in-flight requests up to grace to complete. It serves HTTPS when
+// srv.TLSConfig is set.
+func runServer(ctx context.Context, srv *http.Server, grace time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ListenAndServeTLS("", "")
+		} else {
+			errCh <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down", "grace", grace.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	slog.Info("shutdown complete")
+	return nil
+}
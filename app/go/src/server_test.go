@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// freeAddr returns a loopback address with a port nothing is listening on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// startServer runs srv through runServer until the test ends or cancel is
+// called, waiting until it accepts connections. wait blocks until runServer
+// returns and yields its result.
+func startServer(t *testing.T, srv *http.Server, client *http.Client, baseURL string) (cancel func(), wait func() error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var result error
+	go func() {
+		defer close(done)
+		result = runServer(ctx, srv, 5*time.Second)
+	}()
+	wait = func() error {
+		<-done
+		return result
+	}
+	t.Cleanup(func() {
+		cancel()
+		wait()
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := client.Get(baseURL + "/health")
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never came up: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cancel, wait
+}
+
+func TestRunServerDrainsInFlightRequests(t *testing.T) {
+	addr := freeAddr(t)
+	srv := &http.Server{Addr: addr, Handler: newRouter()}
+	// Without keep-alives the transport can't leave a spare dialed connection
+	// in its pool, which Shutdown would wait on as if it were in flight.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	cancel, wait := startServer(t, srv, client, "http://"+addr)
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := client.Post("http://"+addr+"/sleep", "application/json", strings.NewReader(`{"ms":300}`))
+		if err != nil {
+			t.Errorf("in-flight request failed: %v", err)
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if got := <-status; got != http.StatusOK {
+		t.Errorf("in-flight request status = %d, want 200", got)
+	}
+	if err := wait(); err != nil {
+		t.Errorf("runServer = %v, want nil", err)
+	}
+	if _, err := http.Get("http://" + addr + "/health"); err == nil {
+		t.Error("server still accepting connections after shutdown")
+	}
+}
+
+func TestH2C(t *testing.T) {
+	srv := httptest.NewServer(serverHandler(newRouter(), true))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Post(srv.URL+"/math", "application/json", strings.NewReader(`{"operation":"sum","numbers":[1,2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("proto = %s, want HTTP/2", resp.Proto)
+	}
+	var body mathResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || resp.StatusCode != http.StatusOK || body.Result != 3.0 {
+		t.Errorf("status = %d, result %v, err %v", resp.StatusCode, body.Result, err)
+	}
+	if resp.Header.Get("X-Lambda-Duration-Ms") == "" {
+		t.Error("timing headers missing over h2c")
+	}
+}
+
+func TestSelfSignedTLS(t *testing.T) {
+	tlsConfig, err := newTLSConfig("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	addr := freeAddr(t)
+	srv := &http.Server{Addr: addr, Handler: newRouter(), TLSConfig: tlsConfig}
+	startServer(t, srv, client, "https://"+addr)
+
+	resp, err := client.Get("https://" + addr + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+		t.Errorf("status = %d, TLS state %v", resp.StatusCode, resp.TLS)
+	}
+	if _, err := http.Get("https://" + addr + "/health"); err == nil {
+		t.Error("client without the self-signed root connected")
+	}
+}
+
+func TestReadHeaderTimeout(t *testing.T) {
+	addr := freeAddr(t)
+	srv := &http.Server{Addr: addr, Handler: newRouter(), ReadHeaderTimeout: 100 * time.Millisecond}
+	startServer(t, srv, http.DefaultClient, "http://"+addr)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// Send part of the headers and then stall, as a slowloris client would.
+	if _, err := conn.Write([]byte("GET /health HTTP/1.1\r\nHost: " + addr + "\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	data, err := io.ReadAll(conn)
+	elapsed := time.Since(start)
+
+	if isTimeout(err) {
+		t.Fatalf("connection still open after %v, want it cut off by ReadHeaderTimeout", elapsed)
+	}
+	if len(data) > 0 && !strings.HasPrefix(string(data), "HTTP/1.1 408") {
+		t.Errorf("server answered the incomplete request: %q", data)
+	}
+	if elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("connection closed after %v, want about the 100ms ReadHeaderTimeout", elapsed)
+	}
+}
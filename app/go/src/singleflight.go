@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// singleflightRoutes are pure functions of their request whose work is worth
+// sharing between identical concurrent calls.
+var singleflightRoutes = map[string]bool{
+	"/fibonacci":   true,
+	"/math/primes": true,
+}
+
+// sharedResponse is a response captured once and replayed to every request
+// that shares it.
+type sharedResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// captureWriter copies the response body while passing it through.
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// bufferedResponse is the http.ResponseWriter the shared run writes into.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponse) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponse) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// singleflightPanic carries a panic out of the shared run, which happens on
+// its own goroutine where nothing would recover it.
+type singleflightPanic struct {
+	value interface{}
+}
+
+func (p singleflightPanic) Error() string {
+	return fmt.Sprint("singleflight handler panicked: ", p.value)
+}
+
+// sharedRun is everything the shared run needs from the request that
+// started it, copied up front because that request may finish, and its gin
+// context be reused, before the run does.
+type sharedRun struct {
+	handler gin.HandlerFunc
+	request *http.Request
+	params  gin.Params
+	keys    map[string]interface{}
+	timeout time.Duration
+}
+
+func newSharedRun(c *gin.Context, body []byte) *sharedRun {
+	request := c.Request.Clone(context.WithoutCancel(c.Request.Context()))
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	keys := make(map[string]interface{}, len(c.Keys))
+	for key, value := range c.Keys {
+		keys[key] = value
+	}
+	return &sharedRun{
+		handler: c.Handler(),
+		request: request,
+		params:  c.Params,
+		keys:    keys,
+		timeout: routeTimeout(c.FullPath()),
+	}
+}
+
+// do runs the route handler on a fresh context writing into a buffer. Its
+// context is detached from the client and bounded by the route timeout, so no
+// single caller's cancellation decides the result shared by all.
+func (run *sharedRun) do(engine *gin.Engine) (value interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = singleflightPanic{value: recovered}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(run.request.Context(), run.timeout)
+	defer cancel()
+
+	w := &bufferedResponse{header: make(http.Header)}
+	c := gin.CreateTestContextOnly(w, engine)
+	c.Request = run.request.WithContext(ctx)
+	c.Params = run.params
+	c.Keys = run.keys
+	run.handler(c)
+	c.Writer.WriteHeaderNow()
+
+	return &sharedResponse{status: w.status, contentType: w.header.Get("Content-Type"), body: w.body.Bytes()}, nil
+}
+
+// singleflightMiddleware collapses concurrent requests with the same route,
+// Accept header and body into one run of the route handler, whose response
+// every caller receives, marked X-Singleflight-Shared when more than one did.
+// The run doesn't belong to any one request (see sharedRun), so a caller
+// going away doesn't fail the others, and each caller still gives up at its
+// own deadline. It must be the last middleware, since the shared run skips
+// straight to the handler. It is enabled with ENABLE_SINGLEFLIGHT=true.
+func singleflightMiddleware(engine *gin.Engine) gin.HandlerFunc {
+	var group singleflight.Group
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !singleflightRoutes[route] {
+			c.Next()
+			return
+		}
+
+		body, ok := bufferBody(c)
+		if !ok {
+			return
+		}
+
+		// The ETag hash already identifies a request by route, Accept and body.
+		key := requestETag(route, c.GetHeader("Accept"), body)
+		run := newSharedRun(c, body)
+		var result singleflight.Result
+		select {
+		case result = <-group.DoChan(key, func() (interface{}, error) { return run.do(engine) }):
+		case <-c.Request.Context().Done():
+			respondIfCanceled(c)
+			return
+		}
+		if p, ok := result.Err.(singleflightPanic); ok {
+			panic(p.value)
+		}
+
+		shared := result.Val.(*sharedResponse)
+		if result.Shared {
+			c.Header("X-Singleflight-Shared", "true")
+		}
+		c.Data(shared.status, shared.contentType, shared.body)
+		c.Abort()
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSingleflightRouter serves a stand-in /fibonacci that counts its runs and
+// blocks until release is closed, so every request in a test overlaps.
+func newSingleflightRouter(runs *atomic.Int32, release chan struct{}) *gin.Engine {
+	r := gin.New()
+	r.Use(timeoutMiddleware, singleflightMiddleware(r))
+	r.POST("/fibonacci", func(c *gin.Context) {
+		runs.Add(1)
+		select {
+		case <-release:
+		case <-c.Request.Context().Done():
+			respondIfCanceled(c)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": "55"})
+	})
+	return r
+}
+
+func newFibonacciRequest(ctx context.Context) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/fibonacci", strings.NewReader(`{"n":10}`)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestSingleflightSharesOneRun(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	r := newSingleflightRouter(&runs, release)
+
+	const n = 20
+	responses := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = serve(r, newFibonacciRequest(context.Background()))
+		}(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+	for i, w := range responses {
+		if w.Code != http.StatusOK || w.Body.String() != `{"result":"55"}` {
+			t.Errorf("response %d: %d %s", i, w.Code, w.Body)
+		}
+		if w.Header().Get("X-Singleflight-Shared") != "true" {
+			t.Errorf("response %d not marked shared", i)
+		}
+	}
+}
+
+func TestSingleflightLeaderCancelDoesNotFailWaiters(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	r := newSingleflightRouter(&runs, release)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := make(chan *httptest.ResponseRecorder)
+	go func() { leader <- serve(r, newFibonacciRequest(leaderCtx)) }()
+	time.Sleep(50 * time.Millisecond)
+
+	waiter := make(chan *httptest.ResponseRecorder)
+	go func() { waiter <- serve(r, newFibonacciRequest(context.Background())) }()
+	time.Sleep(50 * time.Millisecond)
+
+	cancelLeader()
+	if w := <-leader; w.Code != statusClientClosedRequest {
+		t.Errorf("leader status = %d, want %d", w.Code, statusClientClosedRequest)
+	}
+	close(release)
+	if w := <-waiter; w.Code != http.StatusOK {
+		t.Errorf("waiter status = %d, want 200", w.Code)
+	}
+}
+
+func TestSingleflightWaiterHonorsOwnDeadline(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	r := newSingleflightRouter(&runs, release)
+
+	go serve(r, newFibonacciRequest(context.Background()))
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	w := serve(r, newFibonacciRequest(ctx))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want 504", w.Code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waiter blocked for %s past its deadline", elapsed)
+	}
+}
+
+func TestSingleflightFibonacci(t *testing.T) {
+	t.Setenv("ENABLE_SINGLEFLIGHT", "true")
+	r := newRouter()
+	w := postJSON(r, "/fibonacci", `{"n":10}`)
+	if w.Code != http.StatusOK || decodeBody(t, w)["result"] != "55" {
+		t.Errorf("got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxSleepMs = 30000
+
+type sleepRequest struct {
+	Ms int `json:"ms"`
+}
+
+type sleepResponse struct {
+	SleptMs int `json:"slept_ms"`
+}
+
+func sleepHandler(c *gin.Context) {
+	var payload sleepRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if payload.Ms < 0 || payload.Ms > maxSleepMs {
+		respondError(c, http.StatusBadRequest, errors.New("ms must be between 0 and 30000"))
+		return
+	}
+
+	timer := time.NewTimer(time.Duration(payload.Ms) * time.Millisecond)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		c.JSON(http.StatusOK, sleepResponse{SleptMs: payload.Ms})
+	case <-c.Request.Context().Done():
+		respondIfCanceled(c)
+	}
+}
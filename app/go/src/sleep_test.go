@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postCanceled sends body to path and cancels the request after wait,
+// returning the response and how long the handler took in total.
+func postCanceled(t *testing.T, path, body string, wait time.Duration) (*httptest.ResponseRecorder, time.Duration) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(wait, cancel)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	w := serve(newRouter(), req)
+	return w, time.Since(start)
+}
+
+func TestSleepCanceledMidRequest(t *testing.T) {
+	w, elapsed := postCanceled(t, "/sleep", `{"ms":5000}`, 50*time.Millisecond)
+	if w.Code != statusClientClosedRequest {
+		t.Errorf("status = %d, want %d", w.Code, statusClientClosedRequest)
+	}
+	if elapsed > time.Second {
+		t.Errorf("handler returned after %v, want shortly after the cancel", elapsed)
+	}
+}
+
+func TestSleepPastRouteTimeout(t *testing.T) {
+	saved := routeTimeouts
+	routeTimeouts = map[string]time.Duration{"/sleep": 50 * time.Millisecond}
+	defer func() { routeTimeouts = saved }()
+
+	start := time.Now()
+	w := postJSON(newRouter(), "/sleep", `{"ms":2000}`)
+	if w.Code != http.StatusGatewayTimeout || errorCode(t, w) != "timeout" {
+		t.Errorf("status = %d, body %s", w.Code, w.Body)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("handler returned after %v, want shortly after the 50ms timeout", elapsed)
+	}
+	if w := postJSON(newRouter(), "/sleep", `{"ms":10}`); w.Code != http.StatusOK {
+		t.Errorf("sleep within the timeout: status = %d", w.Code)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statsBufferSize is how many recent latencies each route keeps.
+var statsBufferSize = 1024
+
+// latencyRing is a fixed-size ring buffer of recent latencies for one route.
+type latencyRing struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	count   int64
+}
+
+func (r *latencyRing) add(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.samples) < cap(r.samples) {
+		r.samples = append(r.samples, d)
+	} else {
+		r.samples[r.next] = d
+	}
+	r.next = (r.next + 1) % cap(r.samples)
+	r.count++
+}
+
+// snapshot returns a sorted copy of the buffered samples and the total
+// number of requests seen.
+func (r *latencyRing) snapshot() ([]time.Duration, int64) {
+	r.mu.Lock()
+	samples := append([]time.Duration(nil), r.samples...)
+	count := r.count
+	r.mu.Unlock()
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples, count
+}
+
+// latencyRecorder tracks a latencyRing per route.
+type latencyRecorder struct {
+	mu     sync.RWMutex
+	routes map[string]*latencyRing
+}
+
+var latencies = &latencyRecorder{routes: make(map[string]*latencyRing)}
+
+func (l *latencyRecorder) record(route string, d time.Duration) {
+	l.mu.RLock()
+	ring, ok := l.routes[route]
+	l.mu.RUnlock()
+	if !ok {
+		l.mu.Lock()
+		if ring, ok = l.routes[route]; !ok {
+			ring = &latencyRing{samples: make([]time.Duration, 0, statsBufferSize)}
+			l.routes[route] = ring
+		}
+		l.mu.Unlock()
+	}
+	ring.add(d)
+}
+
+type routeStats struct {
+	P50Ms float64 `json:"p50_ms"`
+	P90Ms float64 `json:"p90_ms"`
+	P99Ms float64 `json:"p99_ms"`
+	Count int64   `json:"count"`
+}
+
+func (l *latencyRecorder) stats() map[string]routeStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make(map[string]routeStats, len(l.routes))
+	for route, ring := range l.routes {
+		samples, count := ring.snapshot()
+		out[route] = routeStats{
+			P50Ms: percentileMs(samples, 50),
+			P90Ms: percentileMs(samples, 90),
+			P99Ms: percentileMs(samples, 99),
+			Count: count,
+		}
+	}
+	return out
+}
+
+// percentileMs returns the nearest-rank percentile p of sorted samples in
+// milliseconds.
+func percentileMs(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return float64(sorted[rank].Nanoseconds()) / 1e6
+}
+
+func statsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, latencies.stats())
+}
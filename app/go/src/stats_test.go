@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPercentileMs(t *testing.T) {
+	hundred := make([]time.Duration, 100)
+	for i := range hundred {
+		hundred[i] = time.Duration(i+1) * time.Millisecond
+	}
+	single := []time.Duration{7 * time.Millisecond}
+	tests := []struct {
+		samples []time.Duration
+		p, want float64
+	}{
+		{hundred, 0, 1},
+		{hundred, 50, 50},
+		{hundred, 90, 90},
+		{hundred, 99, 99},
+		{hundred, 100, 100},
+		{hundred[:10], 50, 5},
+		{hundred[:10], 99, 10},
+		{single, 0, 7},
+		{single, 50, 7},
+		{single, 100, 7},
+		{nil, 50, 0},
+		{[]time.Duration{1500 * time.Microsecond}, 50, 1.5},
+	}
+	for _, tt := range tests {
+		if got := percentileMs(tt.samples, tt.p); got != tt.want {
+			t.Errorf("p%v of %d samples = %v, want %v", tt.p, len(tt.samples), got, tt.want)
+		}
+	}
+}
+
+func TestLatencyRingWrapAround(t *testing.T) {
+	ring := &latencyRing{samples: make([]time.Duration, 0, 4)}
+	for i := 1; i <= 6; i++ {
+		ring.add(time.Duration(i))
+	}
+	samples, count := ring.snapshot()
+	// The two oldest samples were overwritten; the total still counts them.
+	if want := []time.Duration{3, 4, 5, 6}; !reflect.DeepEqual(samples, want) {
+		t.Errorf("samples = %v, want %v", samples, want)
+	}
+	if count != 6 {
+		t.Errorf("count = %d, want 6", count)
+	}
+
+	for i := 7; i <= 13; i++ {
+		ring.add(time.Duration(i))
+	}
+	if samples, _ := ring.snapshot(); !reflect.DeepEqual(samples, []time.Duration{10, 11, 12, 13}) {
+		t.Errorf("after a second lap samples = %v", samples)
+	}
+}
+
+func TestStatsShape(t *testing.T) {
+	r := newRouter()
+	for i := 0; i < 3; i++ {
+		postJSON(r, "/math", `{"operation":"sum","numbers":[1]}`)
+	}
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	route, ok := decodeBody(t, w)["/math"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("no /math entry in %s", w.Body)
+	}
+	for _, key := range []string{"p50_ms", "p90_ms", "p99_ms", "count"} {
+		if _, ok := route[key]; !ok {
+			t.Errorf("/math stats missing %q: %v", key, route)
+		}
+	}
+	if len(route) != 4 {
+		t.Errorf("/math stats = %v, want exactly p50_ms, p90_ms, p99_ms and count", route)
+	}
+	if count, _ := route["count"].(float64); count < 3 {
+		t.Errorf("count = %v, want at least 3", route["count"])
+	}
+	if p50, p99 := route["p50_ms"].(float64), route["p99_ms"].(float64); p50 > p99 {
+		t.Errorf("p50 %v above p99 %v", p50, p99)
+	}
+}
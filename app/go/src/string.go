@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stringMatchTimeout bounds how long /string may spend matching a pattern.
+var stringMatchTimeout = 2 * time.Second
+
+// stringRequest's Pattern is a pointer so that an empty pattern, which
+// matches everywhere, still counts as given.
+type stringRequest struct {
+	Text        string  `json:"text"`
+	Pattern     *string `json:"pattern" binding:"required_unless=Mode transform"`
+	Replacement *string `json:"replacement"`
+	Mode        string  `json:"mode"`
+	IgnoreCase  bool    `json:"ignore_case"`
+	Multiline   bool    `json:"multiline"`
+	DotAll      bool    `json:"dot_all"`
+	Transform   string  `json:"transform"`
+}
+
+// A /string response takes one of these shapes, depending on mode and on
+// whether a replacement or transform was given.
+type (
+	stringMatchesResponse struct {
+		Matches []string `json:"matches"`
+	}
+	stringResultResponse struct {
+		Result string `json:"result"`
+	}
+	stringGroupsResponse struct {
+		Groups []map[string]string `json:"groups"`
+	}
+	stringLengthResponse struct {
+		Bytes int `json:"bytes"`
+		Runes int `json:"runes"`
+	}
+)
+
+func stringHandler(c *gin.Context) {
+	var payload stringRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	if payload.Mode == "transform" {
+		result, err := transformText(payload.Text, payload.Transform)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	if payload.Mode != "" && payload.Mode != "groups" {
+		respondError(c, http.StatusBadRequest, newAPIError("unsupported_mode", "unsupported mode: "+payload.Mode))
+		return
+	}
+
+	re, ok := compilePattern(c, withFlags(*payload.Pattern, payload.IgnoreCase, payload.Multiline, payload.DotAll))
+	if !ok {
+		return
+	}
+
+	matchWithTimeout(c, func() interface{} {
+		if payload.Mode == "groups" {
+			return stringGroupsResponse{Groups: namedGroups(re, payload.Text)}
+		}
+		if payload.Replacement != nil {
+			return stringResultResponse{Result: re.ReplaceAllString(payload.Text, *payload.Replacement)}
+		}
+		return stringMatchesResponse{Matches: re.FindAllString(payload.Text, -1)}
+	})
+}
+
+// transformText applies a rune-aware text transform. "length" reports both
+// rune and byte counts since they differ for multibyte text.
+func transformText(text, transform string) (interface{}, error) {
+	switch transform {
+	case "upper":
+		return stringResultResponse{Result: strings.ToUpper(text)}, nil
+	case "lower":
+		return stringResultResponse{Result: strings.ToLower(text)}, nil
+	case "reverse":
+		runes := []rune(text)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return stringResultResponse{Result: string(runes)}, nil
+	case "length":
+		return stringLengthResponse{Bytes: len(text), Runes: utf8.RuneCountInString(text)}, nil
+	default:
+		return nil, errors.New("unsupported transform: " + transform)
+	}
+}
+
+// namedGroups returns, for every match, the values of the pattern's named
+// subexpressions keyed by name. Unnamed groups are skipped.
+func namedGroups(re *regexp.Regexp, text string) []map[string]string {
+	names := re.SubexpNames()
+	matches := re.FindAllStringSubmatch(text, -1)
+	groups := make([]map[string]string, 0, len(matches))
+	for _, match := range matches {
+		group := make(map[string]string)
+		for i, name := range names {
+			if i == 0 || name == "" {
+				continue
+			}
+			group[name] = match[i]
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// withFlags prefixes pattern with the RE2 flag group for the enabled
+// options, e.g. (?ims); the pattern is returned as-is when none are set.
+func withFlags(pattern string, ignoreCase, multiline, dotAll bool) string {
+	flags := ""
+	if ignoreCase {
+		flags += "i"
+	}
+	if multiline {
+		flags += "m"
+	}
+	if dotAll {
+		flags += "s"
+	}
+	if flags == "" {
+		return pattern
+	}
+	return "(?" + flags + ")" + pattern
+}
+
+// compilePattern compiles a client-supplied pattern, writing a 400 and
+// returning false if it is malformed.
+func compilePattern(c *gin.Context, pattern string) (*regexp.Regexp, bool) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, newAPIError("invalid_pattern", err.Error()))
+		return nil, false
+	}
+	return re, true
+}
+
+// matchWithTimeout runs match and responds with its result, or with 503 if it
+// does not finish within stringMatchTimeout.
+func matchWithTimeout(c *gin.Context, match func() interface{}) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), stringMatchTimeout)
+	defer cancel()
+
+	// The match runs in its own goroutine so the deadline can be enforced;
+	// the buffered channel lets it finish and exit if we stop waiting.
+	done := make(chan interface{}, 1)
+	go func() {
+		done <- match()
+	}()
+
+	select {
+	case response := <-done:
+		c.JSON(http.StatusOK, response)
+	case <-ctx.Done():
+		if respondIfCanceled(c) {
+			return
+		}
+		respondError(c, http.StatusServiceUnavailable, newAPIError("regex_timeout", "regex timeout"))
+	}
+}
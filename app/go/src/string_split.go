@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stringSplitRequest struct {
+	Text      string  `json:"text"`
+	Pattern   *string `json:"pattern"`
+	Separator *string `json:"separator"`
+	Limit     *int    `json:"limit"`
+}
+
+type stringSplitResponse struct {
+	Count int      `json:"count"`
+	Parts []string `json:"parts"`
+}
+
+// stringSplitHandler splits text by a regex pattern or a literal separator,
+// whichever is supplied. Limit caps the number of parts returned, with the
+// remainder left unsplit in the last one.
+func stringSplitHandler(c *gin.Context) {
+	var payload stringSplitRequest
+	if !bindJSON(c, &payload) {
+		return
+	}
+	if (payload.Pattern == nil) == (payload.Separator == nil) {
+		respondError(c, http.StatusBadRequest, errors.New("exactly one of pattern or separator is required"))
+		return
+	}
+	limit := -1
+	if payload.Limit != nil {
+		if *payload.Limit < 1 {
+			respondError(c, http.StatusBadRequest, errors.New("limit must be positive"))
+			return
+		}
+		limit = *payload.Limit
+	}
+
+	if payload.Separator != nil {
+		parts := strings.SplitN(payload.Text, *payload.Separator, limit)
+		c.JSON(http.StatusOK, stringSplitResponse{Count: len(parts), Parts: parts})
+		return
+	}
+
+	re, ok := compilePattern(c, *payload.Pattern)
+	if !ok {
+		return
+	}
+	matchWithTimeout(c, func() interface{} {
+		parts := re.Split(payload.Text, limit)
+		return stringSplitResponse{Count: len(parts), Parts: parts}
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestStringSplit(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{`{"text":"a,b,,c","separator":","}`, []string{"a", "b", "", "c"}},
+		{`{"text":"a,b,,c","separator":",","limit":2}`, []string{"a", "b,,c"}},
+		{`{"text":"a1b22c333d","pattern":"[0-9]+"}`, []string{"a", "b", "c", "d"}},
+		{`{"text":"a1b22c333d","pattern":"[0-9]+","limit":3}`, []string{"a", "b", "c333d"}},
+	}
+	r := newRouter()
+	for _, tt := range tests {
+		w := postJSON(r, "/string/split", tt.body)
+		var got stringSplitResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.body, w.Code, w.Body)
+			continue
+		}
+		if !reflect.DeepEqual(got.Parts, tt.want) || got.Count != len(tt.want) {
+			t.Errorf("%s: got %+v, want parts %q", tt.body, got, tt.want)
+		}
+	}
+
+	for _, body := range []string{
+		`{"text":"a","pattern":"[","separator":","}`,
+		`{"text":"a"}`,
+		`{"text":"a","pattern":"["}`,
+		`{"text":"a","separator":",","limit":0}`,
+	} {
+		if w := postJSON(r, "/string/split", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStringInvalidPattern(t *testing.T) {
+	r := newRouter()
+	w := postJSON(r, "/string", `{"text":"abc","pattern":"[a-z"}`)
+	if w.Code != http.StatusBadRequest || errorCode(t, w) != "invalid_pattern" {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	w = postJSON(r, "/string", `{"text":"abc","pattern":"[a-z]+"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("request after the bad pattern: status = %d, body %s", w.Code, w.Body)
+	}
+	if matches, _ := decodeBody(t, w)["matches"].([]interface{}); len(matches) != 1 || matches[0] != "abc" {
+		t.Errorf("matches = %v", matches)
+	}
+}
+
+// postString sends body to /string and returns the decoded 200 response.
+func postString(t *testing.T, body string) map[string]interface{} {
+	t.Helper()
+	w := postJSON(newRouter(), "/string", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: status = %d, body %s", body, w.Code, w.Body)
+	}
+	return decodeBody(t, w)
+}
+
+func TestStringReplaceGroupReference(t *testing.T) {
+	body := postString(t, `{"text":"John Smith, Jane Doe","pattern":"(\\w+) (\\w+)","replacement":"$2 $1"}`)
+	if got := body["result"]; got != "Smith John, Doe Jane" {
+		t.Errorf("result = %v", got)
+	}
+	body = postString(t, `{"text":"a-b","pattern":"(?P<first>\\w)-(?P<second>\\w)","replacement":"${second}_${first}"}`)
+	if got := body["result"]; got != "b_a" {
+		t.Errorf("named result = %v", got)
+	}
+}
+
+func TestStringNamedGroups(t *testing.T) {
+	body := postString(t, `{"text":"from 2024-03 to 2025-11","pattern":"(?P<year>\\d{4})-(?P<month>\\d{2})","mode":"groups"}`)
+	groups, _ := body["groups"].([]interface{})
+	if len(groups) != 2 {
+		t.Fatalf("groups = %v", body["groups"])
+	}
+	want := []map[string]interface{}{{"year": "2024", "month": "03"}, {"year": "2025", "month": "11"}}
+	for i, group := range groups {
+		got, _ := group.(map[string]interface{})
+		if len(got) != 2 || got["year"] != want[i]["year"] || got["month"] != want[i]["month"] {
+			t.Errorf("group %d = %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestStringFlags(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{`{"text":"Go GO go","pattern":"go"}`, []string{"go"}},
+		{`{"text":"Go GO go","pattern":"go","ignore_case":true}`, []string{"Go", "GO", "go"}},
+		{`{"text":"one\ntwo\nthree","pattern":"^\\w+$"}`, nil},
+		{`{"text":"one\ntwo\nthree","pattern":"^\\w+$","multiline":true}`, []string{"one", "two", "three"}},
+		{`{"text":"a\nb","pattern":"a.b","dot_all":true}`, []string{"a\nb"}},
+		{`{"text":"X\nx","pattern":"^x$","ignore_case":true,"multiline":true}`, []string{"X", "x"}},
+	}
+	for _, tt := range tests {
+		matches, _ := postString(t, tt.body)["matches"].([]interface{})
+		if len(matches) != len(tt.want) {
+			t.Errorf("%s: matches = %v, want %v", tt.body, matches, tt.want)
+			continue
+		}
+		for i, match := range matches {
+			if match != tt.want[i] {
+				t.Errorf("%s: matches = %v, want %v", tt.body, matches, tt.want)
+				break
+			}
+		}
+	}
+	if got := withFlags("x", true, true, true); got != "(?ims)x" {
+		t.Errorf("withFlags = %q, want (?ims)x", got)
+	}
+}
+
+func TestStringTransformRunes(t *testing.T) {
+	const text = "héllo 👋🏽"
+	body := postString(t, `{"text":"`+text+`","mode":"transform","transform":"reverse"}`)
+	if got := body["result"]; got != "🏽👋 olléh" {
+		t.Errorf("reverse = %q", got)
+	}
+	body = postString(t, `{"text":"`+text+`","mode":"transform","transform":"length"}`)
+	if body["runes"] != 8.0 || body["bytes"] != float64(len(text)) {
+		t.Errorf("length = %v, want 8 runes and %d bytes", body, len(text))
+	}
+	body = postString(t, `{"text":"`+text+`","mode":"transform","transform":"upper"}`)
+	if got := body["result"]; got != "HÉLLO 👋🏽" {
+		t.Errorf("upper = %q", got)
+	}
+}
+
+func TestStringEmptyPattern(t *testing.T) {
+	r := newRouter()
+	w := postJSON(r, "/string", `{"text":"ab","pattern":""}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("empty pattern: status = %d, body %s", w.Code, w.Body)
+	}
+	if matches, _ := decodeBody(t, w)["matches"].([]interface{}); len(matches) != 3 {
+		t.Errorf("matches = %v, want 3 empty matches", matches)
+	}
+	if w := postJSON(r, "/string", `{"text":"ab"}`); w.Code != http.StatusBadRequest || errorCode(t, w) != "validation_failed" {
+		t.Errorf("missing pattern: status = %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestStringRegexTimeout(t *testing.T) {
+	previous := stringMatchTimeout
+	stringMatchTimeout = time.Millisecond
+	t.Cleanup(func() { stringMatchTimeout = previous })
+
+	// Nested quantifiers that never find their "b" would backtrack forever
+	// elsewhere; RE2 stays linear but still takes far longer than 1ms over
+	// 900KB of "a"s.
+	text := strings.Repeat("a", 900<<10)
+	w := postJSON(newRouter(), "/string", `{"text":"`+text+`","pattern":"(a*)*(a+)+b"}`)
+	if w.Code != http.StatusServiceUnavailable || errorCode(t, w) != "regex_timeout" {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handlerTimeout is the deadline applied to routes without an entry in
+// routeTimeouts.
+var handlerTimeout = 60 * time.Second
+
+// routeTimeouts overrides handlerTimeout per route template.
+var routeTimeouts = map[string]time.Duration{}
+
+// parseRouteTimeouts reads "route=ms" pairs separated by commas, e.g.
+// "/sleep=1000,/fibonacci=5000", skipping malformed entries.
+func parseRouteTimeouts(spec string) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(spec, ",") {
+		route, ms, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(ms)
+		if err != nil || n <= 0 {
+			slog.Warn("ignoring invalid route timeout", "entry", entry)
+			continue
+		}
+		timeouts[route] = time.Duration(n) * time.Millisecond
+	}
+	return timeouts
+}
+
+// routeTimeout returns the deadline for route: its routeTimeouts entry, or
+// handlerTimeout.
+func routeTimeout(route string) time.Duration {
+	if timeout, ok := routeTimeouts[route]; ok {
+		return timeout
+	}
+	return handlerTimeout
+}
+
+// timeoutMiddleware bounds each request with its route's deadline and answers
+// 504 if the handler gives up (or returns) without responding. Handlers must
+// honor c.Request.Context() for this to cut work short; one that ignores it
+// simply finishes late.
+func timeoutMiddleware(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), routeTimeout(c.FullPath()))
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+		respondError(c, http.StatusGatewayTimeout, errors.New("handler timeout"))
+	}
+}
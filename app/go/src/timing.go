@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// processStart is when this process began; requests inside coldStartWindow
+// of it count as cold starts.
+var processStart = time.Now()
+
+// coldStartWindow extends X-Cold-Start beyond the very first request. It is
+// zero by default, so only the first request reports a cold start.
+var coldStartWindow time.Duration
+
+var firstRequestServed atomic.Bool
+
+// isColdStart reports whether the current request is the process's first or
+// arrived within coldStartWindow of startup.
+func isColdStart(now time.Time) bool {
+	first := firstRequestServed.CompareAndSwap(false, true)
+	return first || now.Sub(processStart) < coldStartWindow
+}
+
+// timingMiddleware records when the request entered and left the handler
+// chain and reports it through the X-Lambda-* response headers.
+func timingMiddleware(c *gin.Context) {
+	lambdaStart := time.Now()
+	c.Set("lambdaStart", lambdaStart)
+	coldStart := isColdStart(lambdaStart)
+	c.Header("X-Cold-Start", strconv.FormatBool(coldStart))
+
+	// Headers can't change once the body starts, so the end time is taken
+	// just before the first write, or after the chain if nothing was written.
+	tw := &timingWriter{ResponseWriter: c.Writer, start: lambdaStart}
+	c.Writer = tw
+	c.Next()
+	tw.stamp()
+
+	duration := time.Since(lambdaStart)
+	observeRequest(c, duration)
+	requestLogger(c).Debug("handler timing",
+		"route", c.FullPath(),
+		"cold_start", coldStart,
+		"duration_ms", float64(duration.Microseconds())/1000,
+	)
+}
+
+// timingWriter sets the X-Lambda-* headers right before the response header
+// is sent.
+type timingWriter struct {
+	gin.ResponseWriter
+	start   time.Time
+	stamped bool
+}
+
+func (w *timingWriter) stamp() {
+	if w.stamped || w.ResponseWriter.Written() {
+		return
+	}
+	w.stamped = true
+
+	// time.Time keeps a monotonic reading, so Sub is immune to wall-clock
+	// adjustments.
+	end := time.Now()
+	duration := end.Sub(w.start)
+	h := w.Header()
+	h.Set("X-Lambda-Start-Time", w.start.String())
+	h.Set("X-Lambda-End-Time", end.String())
+	h.Set("X-Lambda-Duration", duration.String())
+	h.Set("X-Lambda-Duration-Ms", strconv.FormatFloat(float64(duration.Nanoseconds())/1e6, 'f', 3, 64))
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.stamp()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.stamp()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.stamp()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timingWriter) Flush() {
+	w.stamp()
+	w.ResponseWriter.Flush()
+}
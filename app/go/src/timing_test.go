@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestColdStartHeader(t *testing.T) {
+	firstRequestServed.Store(false)
+	r := newRouter()
+	body := `{"operation":"sum","numbers":[1]}`
+	if got := postJSON(r, "/math", body).Header().Get("X-Cold-Start"); got != "true" {
+		t.Errorf("first request X-Cold-Start = %q, want true", got)
+	}
+	if got := postJSON(r, "/math", body).Header().Get("X-Cold-Start"); got != "false" {
+		t.Errorf("second request X-Cold-Start = %q, want false", got)
+	}
+}
+
+func TestDurationMsHeader(t *testing.T) {
+	w := postJSON(newRouter(), "/sleep", `{"ms":20}`)
+	header := w.Header().Get("X-Lambda-Duration-Ms")
+	ms, err := strconv.ParseFloat(header, 64)
+	if err != nil {
+		t.Fatalf("X-Lambda-Duration-Ms = %q: %v", header, err)
+	}
+	if ms < 20 {
+		t.Errorf("X-Lambda-Duration-Ms = %v, want at least the 20ms slept", ms)
+	}
+	duration, err := time.ParseDuration(w.Header().Get("X-Lambda-Duration"))
+	if err != nil {
+		t.Fatalf("X-Lambda-Duration: %v", err)
+	}
+	if diff := float64(duration.Nanoseconds())/1e6 - ms; diff < -0.001 || diff > 0.001 {
+		t.Errorf("X-Lambda-Duration %v disagrees with X-Lambda-Duration-Ms %v", duration, ms)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Build metadata, set at build time with e.g.
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%FT%TZ)"
+var (
+	version   = "dev"
+	commit    = "dev"
+	buildTime = "dev"
+)
+
+type versionResponse struct {
+	BuildTime string `json:"build_time"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+	Version   string `json:"version"`
+}
+
+func versionHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, versionResponse{
+		BuildTime: buildTime,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+		Version:   version,
+	})
+}
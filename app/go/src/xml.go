@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxXMLDepth caps element nesting in decoded XML documents.
+const maxXMLDepth = 1000
+
+// xmlDocument is a generic XML document in the same shape JSON decodes to:
+// child elements become keys, repeated elements become arrays and leaf
+// elements become strings. The root element's name is not kept, and nested
+// arrays flatten into one run of repeated elements when encoded.
+type xmlDocument map[string]interface{}
+
+func (d *xmlDocument) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	value, err := decodeXMLElement(dec, 1)
+	if err != nil {
+		return err
+	}
+	children, ok := value.(map[string]interface{})
+	if !ok {
+		children = map[string]interface{}{}
+	}
+	*d = children
+	return nil
+}
+
+// decodeXMLElement decodes the content of the element whose start tag was
+// just read, up to and including its end tag.
+func decodeXMLElement(dec *xml.Decoder, depth int) (interface{}, error) {
+	if depth > maxXMLDepth {
+		return nil, errors.New("xml nested too deeply")
+	}
+	children := map[string]interface{}{}
+	var text strings.Builder
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = child
+			case []interface{}:
+				children[name] = append(existing, child)
+			default:
+				children[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(children) > 0 {
+				return children, nil
+			}
+			return text.String(), nil
+		}
+	}
+}
+
+func (d xmlDocument) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return encodeXMLValue(enc, "document", map[string]interface{}(d))
+}
+
+func encodeXMLValue(enc *xml.Encoder, name string, value interface{}) error {
+	if !isXMLName(name) {
+		return fmt.Errorf("%q is not a valid XML element name", name)
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if err := encodeXMLValue(enc, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := encodeXMLValue(enc, key, v[key]); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case nil:
+		return enc.EncodeElement("", start)
+	default:
+		return enc.EncodeElement(fmt.Sprint(v), start)
+	}
+}
+
+// isXMLName reports whether name can be used as an element name as-is.
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+			continue
+		}
+		return false
+	}
+	return true
+}